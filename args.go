@@ -81,20 +81,49 @@ func (args Args) normalize() error {
 // Make all args addressable
 func (args Args) makeAddressable() {
 	for ident, arg := range args {
-		if arg.Kind() != Datas {
-			continue
-		}
-		if arg.Data().Kind() != Regular {
-			continue
-		}
-		oldV := arg.Data().Regular()
-		if oldV.CanAddr() {
-			continue
-		}
+		args[ident] = addressableValue(arg)
+	}
+}
 
-		newV := reflect.New(oldV.Type()).Elem()
-		newV.Set(oldV)
-		args[ident] = MakeDataRegular(newV)
+// addressableValue returns addressable copy of x if it is not addressable regular value, otherwise it returns x.
+func addressableValue(x Value) Value {
+	if x.Kind() != Datas {
+		return x
+	}
+	if x.Data().Kind() != Regular {
+		return x
+	}
+	oldV := x.Data().Regular()
+	if oldV.CanAddr() {
+		return x
+	}
+
+	newV := reflect.New(oldV.Type()).Elem()
+	newV.Set(oldV)
+	return MakeDataRegular(newV)
+}
+
+// makeCopies replaces all regular values in args (including members of packages) with theirs addressable copies.
+// It allows to assign to args without modification of passed values.
+func (args Args) makeCopies() {
+	for ident, arg := range args {
+		switch arg.Kind() {
+		case Datas:
+			if arg.Data().Kind() != Regular {
+				continue
+			}
+			oldV := arg.Data().Regular()
+			newV := reflect.New(oldV.Type()).Elem()
+			newV.Set(oldV)
+			args[ident] = MakeDataRegular(newV)
+		case Package:
+			members := make(Args, len(arg.Package()))
+			for name, member := range arg.Package() {
+				members[name] = member
+			}
+			members.makeCopies()
+			args[ident] = MakePackage(members)
+		}
 	}
 }
 
@@ -109,6 +138,10 @@ func (args Args) validate() error {
 			if arg.Kind() == Datas && arg.Data().Kind() == Regular && !arg.Data().Regular().IsValid() {
 				return errors.New(ident + ": invalid regular data")
 			}
+		case GenericFunc, GenericType:
+			if err := arg.(genericVal).err; err != nil {
+				return errors.New(ident + ": " + err.Error())
+			}
 		}
 	}
 	return nil
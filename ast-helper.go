@@ -69,3 +69,14 @@ func (u upTypesT) pos(n ast.Node) (r Value, err *posError) {
 	}
 	return
 }
+
+// unparen returns e with any enclosing parentheses stripped (like ast.Unparen which is not available before Go 1.22).
+func unparen(e ast.Expr) ast.Expr {
+	for {
+		p, ok := e.(*ast.ParenExpr)
+		if !ok {
+			return e
+		}
+		e = p.X
+	}
+}
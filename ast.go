@@ -29,10 +29,13 @@ func (expr *Expression) astIdent(e *ast.Ident, args Args) (r Value, err *posErro
 	default:
 		var ok bool
 		r, ok = args[e.Name]
+		if !ok && expr != nil {
+			r, ok = expr.spec[e.Name]
+		}
 		if !ok {
-			err = identUndefinedError(e.Name).pos(e)
+			return nil, identUndefinedError(e.Name).pos(e)
 		}
-		return
+		return expr.argValue(r), nil
 	}
 }
 
@@ -49,7 +52,7 @@ func (expr *Expression) astSelectorExpr(e *ast.SelectorExpr, args Args) (r Value
 
 	// Extract field/method name
 	if e.Sel == nil {
-		return nil, &posError{msg: string(*invAstSelectorError()), pos: e.Pos()} // Looks like unreachable if e generated by parsing source (not by hand). It is not possible to use intError.pos here because it cause panic.
+		return nil, invAstSelectorError().at(e.Pos()) // Looks like unreachable if e generated by parsing source (not by hand). It is not possible to use intError.pos here because it cause panic.
 	}
 	name := e.Sel.Name
 
@@ -58,10 +61,10 @@ func (expr *Expression) astSelectorExpr(e *ast.SelectorExpr, args Args) (r Value
 		var ok bool
 		r, ok = x.Package()[name]
 		if !ok {
-			return nil, identUndefinedError("." + name).pos(e)
+			return nil, memberUndefinedError(nil, name).pos(e)
 		}
 
-		return
+		return expr.argValue(r), nil
 	case Datas:
 		xD := x.Data()
 		if xD.Kind() != Regular {
@@ -69,28 +72,78 @@ func (expr *Expression) astSelectorExpr(e *ast.SelectorExpr, args Args) (r Value
 		}
 		xV := xD.Regular()
 
+		// While compiling interface is always nil, so method can be taken only from its type.
+		if xV.Kind() == reflect.Interface && expr.checking() {
+			if method, ok := xV.Type().MethodByName(name); ok {
+				if intErr := expr.allowMethod(xV.Type(), name); intErr != nil {
+					return nil, intErr.pos(e)
+				}
+				return MakeDataRegular(probe(method.Type)), nil
+			}
+			return nil, memberUndefinedError(xV.Type(), name).pos(e)
+		}
+
 		// If kind is pointer than try to get method.
 		// If no method can be get than dereference pointer.
+		var ptrT reflect.Type // type of implicitly dereferenced pointer, if any
 		if xV.Kind() == reflect.Ptr {
 			if method := xV.MethodByName(name); method.IsValid() {
+				_, valueRecv := xV.Type().Elem().MethodByName(name)
+				if valueRecv { // method with value receiver dereferences pointer
+					if intErr := expr.allowDeref(xV.Type()); intErr != nil {
+						return nil, intErr.pos(e)
+					}
+				}
+				if intErr := expr.allowMethod(xV.Type(), name); intErr != nil {
+					return nil, intErr.pos(e)
+				}
+				if valueRecv && xV.IsNil() && !expr.checking() {
+					return nil, nilDerefError().pos(e)
+				}
 				return MakeDataRegular(method), nil
 			}
-			xV = xV.Elem()
+			ptrT = xV.Type()
+			switch {
+			case !xV.IsNil():
+				xV = xV.Elem()
+			case expr.checking():
+				xV = probe(xV.Type().Elem())
+			default: // selecting field or method with value receiver dereferences nil pointer
+				xV = reflect.New(xV.Type().Elem()).Elem()
+				if (xV.Kind() == reflect.Struct && fieldByName(xV, name, expr.pkgPath).IsValid()) || xV.MethodByName(name).IsValid() {
+					if intErr := expr.allowDeref(ptrT); intErr != nil {
+						return nil, intErr.pos(e)
+					}
+					return nil, nilDerefError().pos(e)
+				}
+			}
 		}
 
 		// If kind is struct than try to get field
 		if xV.Kind() == reflect.Struct {
 			if field := fieldByName(xV, name, expr.pkgPath); field.IsValid() {
+				if intErr := expr.allowImplicitDeref(ptrT); intErr != nil {
+					return nil, intErr.pos(e)
+				}
+				if intErr := expr.allowField(xV.Type(), name); intErr != nil {
+					return nil, intErr.pos(e)
+				}
 				return MakeDataRegular(field), nil
 			}
 		}
 
 		// Last case - try to get method (on already dereferenced variable)
 		if method := xV.MethodByName(name); method.IsValid() {
+			if intErr := expr.allowImplicitDeref(ptrT); intErr != nil {
+				return nil, intErr.pos(e)
+			}
+			if intErr := expr.allowMethod(xV.Type(), name); intErr != nil {
+				return nil, intErr.pos(e)
+			}
 			return MakeDataRegular(method), nil
 		}
 
-		return nil, identUndefinedError("." + name).pos(e)
+		return nil, memberUndefinedError(xD.Regular().Type(), name).pos(e)
 	case Type:
 		xT := x.Type()
 		if xT.Kind() == reflect.Interface {
@@ -101,6 +154,9 @@ func (expr *Expression) astSelectorExpr(e *ast.SelectorExpr, args Args) (r Value
 		if !ok || !f.Func.IsValid() {
 			return nil, selectorUndefIdentError(xT, name).pos(e)
 		}
+		if intErr := expr.allowMethod(xT, name); intErr != nil {
+			return nil, intErr.pos(e)
+		}
 		return MakeDataRegular(f.Func), nil
 	default:
 		return nil, invSelectorXError(x).pos(e)
@@ -112,17 +168,36 @@ func (expr *Expression) astBinaryExpr(e *ast.BinaryExpr, args Args) (r Value, er
 	if err != nil {
 		return
 	}
-	y, err := expr.astExprAsData(e.Y, args)
+
+	// Right operand of conditional operator must not be evaluated if left operand determines result.
+	// It is only checked because type of result may depend on it.
+	// While compiling result is determined only by constant left operand (regular one is a probe).
+	var y Data
+	if isLogicalOp(e.Op) && (!expr.checking() || x.Kind() != Regular) && isLogicalOpDetermined(x, e.Op) {
+		y, err = expr.skippedExpr(e.Y, args)
+	} else {
+		y, err = expr.astExprAsData(e.Y, args)
+	}
 	if err != nil {
 		return
 	}
+	if expr.checking() {
+		x, y = probeData(x), probeData(y)
+	}
 
 	// Perform calc depending on operation type
 	switch {
+	case isLogicalOp(e.Op):
+		return upT(logicalOp(x, e.Op, y)).pos(e)
 	case tokenh.IsComparison(e.Op):
 		return upT(compareOp(x, e.Op, y)).pos(e)
 	case tokenh.IsShift(e.Op):
 		return upT(shiftOp(x, e.Op, y)).pos(e)
+	case e.Op == token.ADD:
+		if intErr := expr.allocateConcat(x, y); intErr != nil {
+			return nil, intErr.pos(e)
+		}
+		return upT(binaryOp(x, e.Op, y)).pos(e)
 	default:
 		return upT(binaryOp(x, e.Op, y)).pos(e)
 	}
@@ -147,6 +222,24 @@ func (expr *Expression) astCallExpr(e *ast.CallExpr, args Args) (r Value, err *p
 		return
 	}
 
+	// Top level call may return any number of results (see astMulti)
+	if m := expr.multi; m != nil && m.call == e {
+		r, m.r, err = expr.astCall(e, f, args, true)
+		if err == nil && m.r == nil {
+			m.r = []Value{r}
+		}
+		return
+	}
+
+	r, _, err = expr.astCall(e, f, args, false)
+	return
+}
+
+// astCall performs call e of already resolved function f.
+// If multi is false then call must produce exactly one result r.
+// Otherwise called function may return any number of results, all of them are returned in rs (r is the first of them if any).
+// rs is nil if call produces exactly one result by its nature (conversion, built-in function call, ...).
+func (expr *Expression) astCall(e *ast.CallExpr, f Value, args Args, multi bool) (r Value, rs []Value, err *posError) {
 	// Resolve args
 	var eArgs []Data
 	if f.Kind() != BuiltInFunc { // for built-in funcs required []Value, not []Data
@@ -160,15 +253,47 @@ func (expr *Expression) astCallExpr(e *ast.CallExpr, args Args) (r Value, err *p
 	}
 
 	var intErr *intError
+	if f.Kind() == GenericFunc {
+		f, intErr = f.(genericVal).infer(eArgs, e.Ellipsis != token.NoPos)
+		if intErr != nil {
+			return nil, nil, intErr.pos(e)
+		}
+	}
+
 	switch f.Kind() {
 	case Datas:
 		fD := f.Data()
 		switch fD.Kind() {
 		case Regular:
-			r, intErr = callRegular(fD.Regular(), eArgs, e.Ellipsis != token.NoPos)
+			if intErr = expr.allowCall(e.Fun, fD.Regular(), args); intErr != nil {
+				break
+			}
+			switch {
+			case expr.checking():
+				r, intErr = callRegularCheck(fD.Regular(), eArgs, e.Ellipsis != token.NoPos, expr.options().UnwrapErrors)
+			case multi:
+				var rsV []reflect.Value
+				rsV, intErr = callRegularMulti(fD.Regular(), eArgs, e.Ellipsis != token.NoPos)
+				if intErr = expr.applyPanicPolicy(intErr); intErr != nil {
+					break
+				}
+				rs = make([]Value, len(rsV))
+				for i := range rsV {
+					rs[i] = MakeDataRegular(rsV[i])
+				}
+				if len(rs) > 0 {
+					r = rs[0]
+				}
+				return r, rs, nil
+			default:
+				r, intErr = callRegular(fD.Regular(), eArgs, e.Ellipsis != token.NoPos, expr.options().UnwrapErrors)
+				intErr = expr.applyPanicPolicy(intErr)
+			}
 		default:
 			intErr = callNonFuncError(f)
 		}
+	case GenericType:
+		intErr = genericNotInstantiatedError(f.(genericVal))
 	case BuiltInFunc:
 		eArgs := make([]Value, len(e.Args))
 		for i := range e.Args {
@@ -177,18 +302,28 @@ func (expr *Expression) astCallExpr(e *ast.CallExpr, args Args) (r Value, err *p
 				return
 			}
 		}
-		r, intErr = callBuiltInFunc(f.BuiltInFunc(), eArgs, e.Ellipsis != token.NoPos)
+		if expr.checking() && f.BuiltInFunc() == "make" {
+			r, intErr = builtInMakeCheck(eArgs, e.Ellipsis != token.NoPos)
+		} else if intErr = expr.allocateBuiltIn(f.BuiltInFunc(), eArgs); intErr == nil {
+			r, intErr = callBuiltInFunc(f.BuiltInFunc(), eArgs, e.Ellipsis != token.NoPos)
+			intErr = expr.applyPanicPolicy(intErr)
+			if intErr == nil && f.BuiltInFunc() == "append" {
+				intErr = expr.allocateAppend(eArgs[0], r)
+			}
+		}
 	case Type:
 		if e.Ellipsis != token.NoPos {
-			return nil, convertWithEllipsisError(f.Type()).pos(e)
+			return nil, nil, convertWithEllipsisError(f.Type()).pos(e)
 		}
 		r, intErr = convertCall(f.Type(), eArgs)
 	default:
 		intErr = callNonFuncError(f)
 	}
 
-	err = intErr.pos(e)
-	return
+	if intErr != nil {
+		return nil, nil, intErr.pos(e)
+	}
+	return r, nil, nil
 }
 
 func (expr *Expression) astStarExpr(e *ast.StarExpr, args Args) (r Value, err *posError) {
@@ -201,6 +336,15 @@ func (expr *Expression) astStarExpr(e *ast.StarExpr, args Args) (r Value, err *p
 	case v.Kind() == Type:
 		return MakeType(reflect.PtrTo(v.Type())), nil
 	case v.Kind() == Datas && v.Data().Kind() == Regular && v.Data().Regular().Kind() == reflect.Ptr:
+		if intErr := expr.allowDeref(v.Data().Regular().Type()); intErr != nil {
+			return nil, intErr.pos(e)
+		}
+		if xV := v.Data().Regular(); xV.IsNil() {
+			if expr.checking() {
+				return MakeDataRegular(probe(xV.Type().Elem())), nil
+			}
+			return nil, nilDerefError().pos(e)
+		}
 		return MakeDataRegular(v.Data().Regular().Elem()), nil
 	default:
 		return nil, indirectInvalError(v).pos(e)
@@ -212,6 +356,31 @@ func (expr *Expression) astUnaryExpr(e *ast.UnaryExpr, args Args) (r Value, err
 	if err != nil {
 		return
 	}
+
+	// Do not receive from channel while compiling.
+	if e.Op == token.ARROW && expr.checking() && x.Kind() == Regular {
+		if xT := x.Regular().Type(); xT.Kind() == reflect.Chan && xT.ChanDir()&reflect.RecvDir != 0 {
+			if intErr := expr.allowRecv(xT); intErr != nil {
+				return nil, intErr.pos(e)
+			}
+			return MakeDataRegular(probe(xT.Elem())), nil
+		}
+	}
+
+	// Receiving from channel must respect context.
+	if e.Op == token.ARROW && x.Kind() == Regular {
+		if xV := x.Regular(); xV.Kind() == reflect.Chan && xV.Type().ChanDir()&reflect.RecvDir != 0 {
+			rV, ok, intErr := expr.recv(xV)
+			if intErr != nil {
+				return nil, intErr.pos(e)
+			}
+			if !ok {
+				rV = reflect.New(xV.Type().Elem()).Elem() // zero value if channel is closed
+			}
+			return MakeDataRegular(rV), nil
+		}
+	}
+
 	return upT(unaryOp(e.Op, x)).pos(e)
 }
 
@@ -295,10 +464,17 @@ func (expr *Expression) astArrayType(e *ast.ArrayType, args Args) (r Value, err
 }
 
 func (expr *Expression) astIndexExpr(e *ast.IndexExpr, args Args) (r Value, err *posError) {
-	x, err := expr.astExprAsData(e.X, args)
+	xV, err := expr.astExpr(e.X, args)
 	if err != nil {
 		return
 	}
+	if g, ok := xV.(genericVal); ok {
+		return expr.astGenericInstance(e, g, []ast.Expr{e.Index}, args)
+	}
+	if xV.Kind() != Datas {
+		return nil, notExprError(xV).pos(e.X)
+	}
+	x := xV.Data()
 
 	i, err := expr.astExprAsData(e.Index, args)
 	if err != nil {
@@ -308,16 +484,26 @@ func (expr *Expression) astIndexExpr(e *ast.IndexExpr, args Args) (r Value, err
 	var intErr *intError
 	switch x.Kind() {
 	case Regular:
-		switch x.Regular().Kind() {
-		case reflect.Map:
+		switch {
+		case x.Regular().Kind() == reflect.Map:
 			r, intErr = indexMap(x.Regular(), i)
+		case expr.checking() && (x.Regular().Kind() != reflect.Array || !i.IsConst()): // length is unknown while compiling
+			r, intErr = indexStatic(x, i)
 		default:
 			r, intErr = indexOther(x.Regular(), i)
 		}
 	case TypedConst:
-		r, intErr = indexConstant(x.TypedConst().Untyped(), i)
+		if expr.checking() && !i.IsConst() {
+			r, intErr = indexStatic(x, i)
+		} else {
+			r, intErr = indexConstant(x.TypedConst().Untyped(), i)
+		}
 	case UntypedConst:
-		r, intErr = indexConstant(x.UntypedConst(), i)
+		if expr.checking() && !i.IsConst() {
+			r, intErr = indexStatic(x, i)
+		} else {
+			r, intErr = indexConstant(x.UntypedConst(), i)
+		}
 	default:
 		intErr = invIndexOpError(x, i)
 	}
@@ -332,6 +518,7 @@ func (expr *Expression) astSliceExpr(e *ast.SliceExpr, args Args) (r Value, err
 		return
 	}
 
+	constIndexes := true // false if at least one index is not a constant
 	indexResolve := func(e ast.Expr) (iInt int, err1 *posError) {
 		var i Data
 		if e != nil {
@@ -339,6 +526,7 @@ func (expr *Expression) astSliceExpr(e *ast.SliceExpr, args Args) (r Value, err
 			if err1 != nil {
 				return
 			}
+			constIndexes = constIndexes && i.IsConst()
 		}
 
 		var intErr *intError
@@ -391,11 +579,17 @@ func (expr *Expression) astSliceExpr(e *ast.SliceExpr, args Args) (r Value, err
 		return nil, sliceInvTypeError(x).pos(e.X)
 	}
 
+	// Bounds of constant string and array are known at compile time
+	static := constIndexes && (x.Kind() != Regular || v.Kind() == reflect.Array || v.Kind() == reflect.Ptr)
+
 	var intErr *intError
-	if e.Slice3 {
-		r, intErr = slice3(v, low, high, max)
-	} else {
-		r, intErr = slice2(v, low, high)
+	switch {
+	case expr.checking() && (x.Kind() == Regular || !constIndexes): // length is unknown while compiling
+		r, intErr = sliceStatic(v, e.Slice3)
+	case e.Slice3:
+		r, intErr = slice3(v, low, high, max, static)
+	default:
+		r, intErr = slice2(v, low, high, static)
 	}
 
 	err = intErr.pos(e)
@@ -435,6 +629,9 @@ func (expr *Expression) astCompositeLit(e *ast.CompositeLit, args Args) (r Value
 			_, withKeys = e.Elts[0].(*ast.KeyValueExpr)
 		}
 
+		if intErr = expr.allocate(sizeOfElems(vT, 0)); intErr != nil {
+			break
+		}
 		switch withKeys {
 		case true:
 			elts := make(map[string]Data)
@@ -472,6 +669,7 @@ func (expr *Expression) astCompositeLit(e *ast.CompositeLit, args Args) (r Value
 	case reflect.Array, reflect.Slice:
 		elts := make(map[int]Data)
 		nextIndex := 0
+		length := 0 // length of slice
 		for i := range e.Elts {
 			var valueExpr ast.Expr
 			if kve, ok := e.Elts[i].(*ast.KeyValueExpr); ok {
@@ -514,8 +712,14 @@ func (expr *Expression) astCompositeLit(e *ast.CompositeLit, args Args) (r Value
 				return
 			}
 			nextIndex++
+			if nextIndex > length {
+				length = nextIndex
+			}
 		}
 
+		if intErr = expr.allocate(sizeOfElems(vT, length)); intErr != nil {
+			break
+		}
 		r, intErr = compositeLitArrayLike(vT, elts)
 	case reflect.Map:
 		elts := make(map[Data]Data)
@@ -536,6 +740,9 @@ func (expr *Expression) astCompositeLit(e *ast.CompositeLit, args Args) (r Value
 			}
 		}
 
+		if intErr = expr.allocate(sizeOfElems(vT, len(elts))); intErr != nil {
+			break
+		}
 		r, intErr = compositeLitMap(vT, elts)
 	default:
 		return nil, initInvTypeError(vT).pos(e.Type)
@@ -546,25 +753,38 @@ func (expr *Expression) astCompositeLit(e *ast.CompositeLit, args Args) (r Value
 }
 
 func (expr *Expression) astTypeAssertExpr(e *ast.TypeAssertExpr, args Args) (r Value, err *posError) {
-	x, err := expr.astExprAsData(e.X, args)
+	x, t, err := expr.astTypeAssertExprOperands(e, args)
 	if err != nil {
 		return
 	}
-	if x.Kind() != Regular || x.Regular().Kind() != reflect.Interface {
-		return nil, typeAssertLeftInvalError(x).pos(e)
+	if expr.checking() {
+		return MakeDataRegular(probe(t)), nil
+	}
+	rV, ok, _ := reflecth.TypeAssert(x, t)
+	if !ok {
+		return nil, typeAssertFalseError(x, t).pos(e)
 	}
-	t, err := expr.astExprAsType(e.Type, args)
+	return MakeDataRegular(rV), nil
+}
+
+// astTypeAssertExprOperands calculates operands of type assertion and checks what assertion is possible.
+func (expr *Expression) astTypeAssertExprOperands(e *ast.TypeAssertExpr, args Args) (x reflect.Value, t reflect.Type, err *posError) {
+	xD, err := expr.astExprAsData(e.X, args)
 	if err != nil {
 		return
 	}
-	rV, ok, valid := reflecth.TypeAssert(x.Regular(), t)
-	if !valid {
-		return nil, typeAssertImposError(x.Regular(), t).pos(e)
+	if xD.Kind() != Regular || xD.Regular().Kind() != reflect.Interface {
+		return reflect.Value{}, nil, typeAssertLeftInvalError(xD).pos(e)
 	}
-	if !ok {
-		return nil, typeAssertFalseError(x.Regular(), t).pos(e)
+	x = xD.Regular()
+	t, err = expr.astExprAsType(e.Type, args)
+	if err != nil {
+		return
 	}
-	return MakeDataRegular(rV), nil
+	if _, _, valid := reflecth.TypeAssert(x, t); !valid {
+		return reflect.Value{}, nil, typeAssertImposError(x, t).pos(e)
+	}
+	return
 }
 
 func (expr *Expression) astMapType(e *ast.MapType, args Args) (r Value, err *posError) {
@@ -593,7 +813,7 @@ func (expr *Expression) astMapType(e *ast.MapType, args Args) (r Value, err *pos
 func (expr *Expression) astStructType(e *ast.StructType, args Args) (r Value, err *posError) {
 	// Looks like e.Incomplete does not mean anything in our case.
 	if e.Fields == nil {
-		return nil, &posError{msg: string(*invAstNilStructFieldsError()), pos: e.Pos()} // Looks like unreachable if e generated by parsing source (not by hand). It is not possible to use intError.pos here because it cause panic.
+		return nil, invAstNilStructFieldsError().at(e.Pos()) // Looks like unreachable if e generated by parsing source (not by hand). It is not possible to use intError.pos here because it cause panic.
 	}
 
 	extractTag := func(l *ast.BasicLit) (tag reflect.StructTag, err *posError) {
@@ -658,7 +878,7 @@ func (expr *Expression) astStructType(e *ast.StructType, args Args) (r Value, er
 
 func (expr *Expression) astInterfaceType(e *ast.InterfaceType, args Args) (r Value, err *posError) {
 	if e.Methods == nil {
-		return nil, &posError{msg: string(*invAstNilInterfaceMethodsError()), pos: e.Pos()} // Looks like unreachable if e generated by parsing source (not by hand). It is not possible to use intError.pos here because it cause panic.
+		return nil, invAstNilInterfaceMethodsError().at(e.Pos()) // Looks like unreachable if e generated by parsing source (not by hand). It is not possible to use intError.pos here because it cause panic.
 	}
 	if len(e.Methods.List) != 0 {
 		return nil, unsupportedInterfaceTypeError().pos(e)
@@ -700,6 +920,38 @@ func (expr *Expression) astExprAsType(e ast.Expr, args Args) (r reflect.Type, er
 }
 
 func (expr *Expression) astExpr(e ast.Expr, args Args) (r Value, err *posError) {
+	// Fast path for plain evaluation (uncompiled, unlimited, not traced and not cancelable)
+	if expr == nil || expr.budget == nil && expr.check == nil && expr.folded == nil && expr.ctx == nil && expr.opts.Tracer == nil {
+		return expr.astExprNode(e, args)
+	}
+
+	if expr.budget != nil {
+		if err := expr.enter(); err != nil {
+			return nil, err.pos(e)
+		}
+		defer expr.exit()
+	}
+	if expr.checking() {
+		return expr.checkExpr(e, args)
+	}
+	if tracer := expr.opts.Tracer; tracer != nil && e != nil {
+		return expr.traceExpr(tracer, e, args)
+	}
+	return expr.astExprValue(e, args)
+}
+
+// astExprValue evaluates e taking into account values computed at compile time.
+func (expr *Expression) astExprValue(e ast.Expr, args Args) (r Value, err *posError) {
+	if err := expr.contextError(); err != nil {
+		return nil, err.pos(e)
+	}
+	if r, ok := expr.foldedValue(e); ok {
+		return r, nil
+	}
+	return expr.astExprNode(e, args)
+}
+
+func (expr *Expression) astExprNode(e ast.Expr, args Args) (r Value, err *posError) {
 	if e == nil {
 		return nil, invAstNilError().noPos()
 	}
@@ -731,6 +983,8 @@ func (expr *Expression) astExpr(e ast.Expr, args Args) (r Value, err *posError)
 		return expr.astArrayType(v, args)
 	case *ast.IndexExpr:
 		return expr.astIndexExpr(v, args)
+	case *ast.IndexListExpr:
+		return expr.astIndexListExpr(v, args)
 	case *ast.SliceExpr:
 		return expr.astSliceExpr(v, args)
 	case *ast.CompositeLit:
@@ -743,9 +997,10 @@ func (expr *Expression) astExpr(e ast.Expr, args Args) (r Value, err *posError)
 		return expr.astStructType(v, args)
 	case *ast.InterfaceType:
 		return expr.astInterfaceType(v, args)
+	case *ast.FuncLit:
+		return expr.astFuncLit(v, args)
 	default:
 		// BadExpr - no need to implement
-		// FuncLit - do not want to implement (too hard, this project only evaluate expression)
 		// KeyValueExpr - implemented in-place, not here
 		return nil, invAstUnsupportedError(e).pos(e)
 	}
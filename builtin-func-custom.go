@@ -0,0 +1,115 @@
+package eval
+
+import (
+	"errors"
+	"github.com/apaxa-go/helper/strconvh"
+	"go/token"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+)
+
+// BuiltInFuncSpec describes custom built-in function (see RegisterBuiltInFunc).
+type BuiltInFuncSpec struct {
+	// MinArgs and MaxArgs specify allowed number of arguments.
+	// Negative MaxArgs means no upper limit.
+	MinArgs, MaxArgs int
+	// Ellipsis allows ellipsis notation for the last argument ("f(a, b...)").
+	Ellipsis bool
+	// Func implements built-in function.
+	// It gets arguments as is: types, packages, constants (typed & untyped), untyped booleans, nil and regular variables.
+	// ellipsis true if last argument has ellipsis notation.
+	// Func may return BuiltInArgError to report invalid argument in the same way as native built-in functions do.
+	// Any other error is reported as is.
+	//
+	// Func is also called by Compile with arguments of valid kinds and types but with arbitrary values of regular variables.
+	// So Func must not have side effects, and result which is not a regular variable (type or constant) must not depend on values of regular variables.
+	Func func(args []Value, ellipsis bool) (Value, error)
+}
+
+// BuiltInArgError may be returned by custom built-in function to report invalid argument (see BuiltInFuncSpec.Func).
+type BuiltInArgError struct {
+	Index int // index of invalid argument
+}
+
+// Error implements standard error interface.
+func (err BuiltInArgError) Error() string {
+	return "invalid argument #" + strconvh.FormatInt(err.Index)
+}
+
+// customBuiltInFuncs holds registered functions.
+// Map is never modified after it is stored, registration stores a new map, so evaluation reads it without locking.
+// Functions may be only added, so once identifier is resolved to custom built-in function it remains the same function.
+var customBuiltInFuncs struct {
+	sync.Mutex // serializes registrations
+	m          atomic.Pointer[map[string]BuiltInFuncSpec]
+}
+
+// RegisterBuiltInFunc registers custom built-in function with given name.
+// Registered function is available in all expressions in the same way as native built-in functions ("len", "make", ...): it may not be shadowed by arguments.
+// RegisterBuiltInFunc is intended to be called from init functions.
+// It panics if name is not a valid identifier, is already used by predeclared identifier or by other registered function, or if spec.Func is nil.
+func RegisterBuiltInFunc(name string, spec BuiltInFuncSpec) {
+	switch {
+	case !token.IsIdentifier(name) || name == "_":
+		panic("eval: invalid name of built-in function " + name)
+	case name == "true" || name == "false" || name == "nil" || isBuiltInType(name):
+		panic("eval: name " + name + " is predeclared")
+	case spec.Func == nil:
+		panic("eval: nil implementation of built-in function " + name)
+	}
+
+	customBuiltInFuncs.Lock()
+	defer customBuiltInFuncs.Unlock()
+	if _, ok := customBuiltInFunc(name); ok || isNativeBuiltInFunc(name) {
+		panic("eval: built-in function " + name + " is already registered")
+	}
+	var old map[string]BuiltInFuncSpec
+	if p := customBuiltInFuncs.m.Load(); p != nil {
+		old = *p
+	}
+	m := make(map[string]BuiltInFuncSpec, len(old)+1)
+	for k, v := range old {
+		m[k] = v
+	}
+	m[name] = spec
+	customBuiltInFuncs.m.Store(&m)
+}
+
+func customBuiltInFunc(name string) (spec BuiltInFuncSpec, ok bool) {
+	if p := customBuiltInFuncs.m.Load(); p != nil {
+		spec, ok = (*p)[name]
+	}
+	return
+}
+
+func callCustomBuiltInFunc(f string, spec BuiltInFuncSpec, args []Value, ellipsis bool) (r Value, err *intError) {
+	if ellipsis && !spec.Ellipsis {
+		return nil, callBuiltInWithEllipsisError(f)
+	}
+	if len(args) < spec.MinArgs {
+		return nil, callBuiltInArgsCountMismError(f, spec.MinArgs, len(args))
+	}
+	if spec.MaxArgs >= 0 && len(args) > spec.MaxArgs {
+		return nil, callBuiltInArgsCountMismError(f, spec.MaxArgs, len(args))
+	}
+
+	defer func() {
+		if rec := recover(); rec != nil {
+			r, err = nil, callPanicError(rec, debug.Stack())
+		}
+	}()
+	// Func may retain its arguments, so it gets a copy and args of native built-in functions do not escape.
+	r, goErr := spec.Func(append([]Value(nil), args...), ellipsis)
+	if goErr != nil {
+		var argErr BuiltInArgError
+		if errors.As(goErr, &argErr) && argErr.Index >= 0 && argErr.Index < len(args) {
+			return nil, invCustomBuiltInArgError(f, args, argErr.Index)
+		}
+		return nil, callReturnedError(goErr)
+	}
+	if r == nil {
+		return nil, customBuiltInNilResultError(f)
+	}
+	return r, nil
+}
@@ -0,0 +1,134 @@
+package eval
+
+import (
+	"errors"
+	"github.com/apaxa-go/helper/goh/constanth"
+	"github.com/apaxa-go/helper/reflecth"
+	"github.com/apaxa-go/helper/strconvh"
+	"reflect"
+	"testing"
+)
+
+func init() {
+	RegisterBuiltInFunc("typeof", BuiltInFuncSpec{
+		MinArgs: 1,
+		MaxArgs: 1,
+		Func: func(args []Value, ellipsis bool) (Value, error) {
+			if args[0].Kind() != Datas || args[0].Data().Kind() != Regular {
+				return nil, BuiltInArgError{0}
+			}
+			return MakeType(args[0].Data().Regular().Type()), nil
+		},
+	})
+	RegisterBuiltInFunc("sizeof", BuiltInFuncSpec{
+		MinArgs: 1,
+		MaxArgs: 1,
+		Func: func(args []Value, ellipsis bool) (Value, error) {
+			if args[0].Kind() != Type {
+				return nil, BuiltInArgError{0}
+			}
+			size := int(args[0].Type().Size())
+			return MakeDataTypedConst(constanth.MustMakeTypedValue(constanth.MakeInt(size), reflecth.TypeUintptr())), nil
+		},
+	})
+	RegisterBuiltInFunc("zero", BuiltInFuncSpec{
+		MinArgs:  1,
+		MaxArgs:  -1,
+		Ellipsis: true,
+		Func: func(args []Value, ellipsis bool) (Value, error) {
+			if args[len(args)-1].Kind() != Type {
+				return nil, BuiltInArgError{len(args) - 1}
+			}
+			if len(args) > 1 {
+				return nil, errors.New("zero: only last argument is used")
+			}
+			return MakeDataRegular(reflect.New(args[0].Type()).Elem()), nil
+		},
+	})
+}
+
+func TestRegisterBuiltInFunc(t *testing.T) {
+	type testElement struct {
+		expr string
+		r    interface{}
+		err  string
+	}
+
+	args := ArgsFromInterfaces(ArgsI{"a": int16(5), "s": "str"})
+	tests := []testElement{
+		{"typeof(a)(7)", int16(7), ""},
+		{"sizeof(typeof(a))+1", uintptr(3), ""},
+		{"sizeof(int32)", uintptr(4), ""},
+		{"zero(string)+s", "str", ""},
+		{"typeof(1)", nil, "expression:1:1: invalid argument 1 (type untyped constant) for typeof"},
+		{"sizeof(a)", nil, "expression:1:1: invalid argument 5 (type int16) for sizeof"},
+		{"typeof(int)", nil, "expression:1:1: type is not an expression"},
+		{"typeof(a, a)", nil, "expression:1:1: too many arguments in call to typeof"},
+		{"typeof()", nil, "expression:1:1: not enough arguments in call to typeof"},
+		{"typeof(a...)", nil, "expression:1:1: invalid use of ... with builtin typeof"},
+		{"zero(a, 1)", nil, "expression:1:1: invalid argument #1 1 (type untyped constant) for zero"},
+		{"zero(a, int)", nil, "expression:1:1: zero: only last argument is used"},
+		{"1+typeof(a)", nil, "expression:1:3: type is not an expression"},
+	}
+
+	for _, test := range tests {
+		e, err := ParseString(test.expr, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := e.EvalToInterface(args)
+		switch {
+		case test.err == "" && (err != nil || r != test.r):
+			t.Errorf("%v: expect %v, got %v %v", test.expr, test.r, r, err)
+		case test.err != "" && (err == nil || err.Error() != test.err):
+			t.Errorf("%v: expect error %q, got %v", test.expr, test.err, err)
+		}
+	}
+
+	// Built-in functions may not be shadowed by arguments.
+	e, err := ParseString("sizeof(int8)", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := e.EvalToInterface(ArgsFromInterfaces(ArgsI{"sizeof": func(int) int { return 0 }}))
+	if err != nil || r != uintptr(1) {
+		t.Errorf("expect %v, got %v %v", uintptr(1), r, err)
+	}
+
+	// Invalid registrations
+	for _, name := range []string{"", "_", "1a", "len", "int", "nil", "typeof"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%q: expect panic", name)
+				}
+			}()
+			RegisterBuiltInFunc(name, BuiltInFuncSpec{Func: func([]Value, bool) (Value, error) { return nil, nil }})
+		}()
+	}
+}
+
+func TestRegisterBuiltInFuncConcurrent(t *testing.T) {
+	e, err := ParseString("sizeof(typeof(a))", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := ArgsFromInterfaces(ArgsI{"a": int16(5)})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			RegisterBuiltInFunc("concurrent"+strconvh.FormatInt(i), BuiltInFuncSpec{Func: func([]Value, bool) (Value, error) { return nil, nil }})
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		if r, err := e.EvalToInterface(args); err != nil || r != uintptr(2) {
+			t.Fatalf("expect %v, got %v %v", uintptr(2), r, err)
+		}
+	}
+	<-done
+	if !isBuiltInFunc("concurrent99") {
+		t.Error("expect registered function")
+	}
+}
@@ -9,6 +9,14 @@ import (
 )
 
 func isBuiltInFunc(ident string) bool {
+	if isNativeBuiltInFunc(ident) {
+		return true
+	}
+	_, ok := customBuiltInFunc(ident)
+	return ok
+}
+
+func isNativeBuiltInFunc(ident string) bool {
 	switch ident {
 	case "len", "cap", "complex", "real", "imag", "new", "make", "append":
 		return true
@@ -18,6 +26,9 @@ func isBuiltInFunc(ident string) bool {
 }
 
 func callBuiltInFunc(f string, args []Value, ellipsis bool) (r Value, err *intError) {
+	if spec, ok := customBuiltInFunc(f); ok {
+		return callCustomBuiltInFunc(f, spec, args, ellipsis)
+	}
 	if f != "append" && ellipsis {
 		return nil, callBuiltInWithEllipsisError(f)
 	}
@@ -97,47 +108,57 @@ func builtInNew(t Value) (r Value, err *intError) {
 }
 
 func builtInMake(v []Value) (r Value, err *intError) {
+	t, n, m, err := builtInMakeArgs(v)
+	if err != nil {
+		return
+	}
+	return builtInMakeParsed(t, n, m)
+}
+
+// builtInMakeArgs extracts arguments of built-in function make.
+// -1 in n or m means no arg passed.
+func builtInMakeArgs(v []Value) (t reflect.Type, n, m int, err *intError) {
 	const fn = "make"
 	if len(v) < 1 || len(v) > 3 {
-		return nil, callBuiltInArgsCountMismError(fn, 1, len(v))
+		return nil, 0, 0, callBuiltInArgsCountMismError(fn, 1, len(v))
 	}
 
 	// calc type
 	if v[0].Kind() != Type {
-		return nil, notTypeError(v[0])
+		return nil, 0, 0, notTypeError(v[0])
 	}
-	t := v[0].Type()
+	t = v[0].Type()
 
 	// calc int args; -1 means no arg passed
-	var n, m int = -1, -1
+	n, m = -1, -1
 	switch len(v) {
 	case 3:
 		if v[2].Kind() != Datas {
-			return nil, makeNotIntArgError(t, 2, v[2])
+			return nil, 0, 0, makeNotIntArgError(t, 2, v[2])
 		}
 		var ok bool
 		m, ok = v[2].Data().AsInt()
 		if !ok {
-			return nil, makeNotIntArgError(t, 2, v[2])
+			return nil, 0, 0, makeNotIntArgError(t, 2, v[2])
 		}
 		if m < 0 {
-			return nil, makeNegArgError(t, 2)
+			return nil, 0, 0, makeNegArgError(t, 2)
 		}
 		fallthrough
 	case 2:
 		if v[1].Kind() != Datas {
-			return nil, makeNotIntArgError(t, 1, v[1])
+			return nil, 0, 0, makeNotIntArgError(t, 1, v[1])
 		}
 		var ok bool
 		n, ok = v[1].Data().AsInt()
 		if !ok {
-			return nil, makeNotIntArgError(t, 1, v[1])
+			return nil, 0, 0, makeNotIntArgError(t, 1, v[1])
 		}
 		if n < 0 {
-			return nil, makeNegArgError(t, 1)
+			return nil, 0, 0, makeNegArgError(t, 1)
 		}
 	}
-	return builtInMakeParsed(t, n, m)
+	return
 }
 
 // BUG(a.bekker): make(<map>,n) ignore n (but check it type).
@@ -186,9 +207,9 @@ func builtInLenConstant(v constant.Value) (r Value, err *intError) {
 
 func builtInLenRegular(v reflect.Value) (r Value, err *intError) {
 	const fn = "len"
-	// Resolve pointer to array
-	if v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Array {
-		v = v.Elem()
+	// Resolve pointer to array (length of array is a part of type, so pointer may be nil)
+	if v.Kind() == reflect.Ptr && v.Type().Elem().Kind() == reflect.Array {
+		v = reflect.Zero(v.Type().Elem())
 	}
 
 	switch v.Kind() {
@@ -220,9 +241,9 @@ func builtInLen(v Data) (r Value, err *intError) {
 
 func builtInCapRegular(v reflect.Value) (r Value, err *intError) {
 	const fn = "cap"
-	// Resolve pointer to array
-	if v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Array {
-		v = v.Elem()
+	// Resolve pointer to array (length of array is a part of type, so pointer may be nil)
+	if v.Kind() == reflect.Ptr && v.Type().Elem().Kind() == reflect.Array {
+		v = reflect.Zero(v.Type().Elem())
 	}
 
 	switch v.Kind() {
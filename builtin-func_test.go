@@ -12,7 +12,7 @@ func TestIsBuiltInFunc(t *testing.T) {
 			t.Error("expect " + f + " to be an built-in function")
 		}
 		if _, intErr := callBuiltInFunc(f, nil, false); *intErr == *(undefIdentError(f)) {
-			t.Error("expect for " + f + " not \"" + string(*intErr) + "\" error")
+			t.Error("expect for " + f + " not \"" + intErr.msg + "\" error")
 		}
 	}
 }
@@ -2,71 +2,125 @@ package eval
 
 import (
 	"reflect"
+	"runtime/debug"
 )
 
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
 // ellipsis true if last argument has ellipsis notation ("f(a,b,c...)").
-func callRegular(f reflect.Value, args []Data, ellipsis bool) (r Value, err *intError) {
+// unwrap true if f may returns (T, error) (see EvalOptions.UnwrapErrors).
+func callRegular(f reflect.Value, args []Data, ellipsis, unwrap bool) (r Value, err *intError) {
 	if f.Kind() != reflect.Func {
 		return nil, callNonFuncError(MakeDataRegular(f))
 	}
-	fT := f.Type()
-	if fT.NumOut() != 1 {
-		return nil, callResultCountMismError(fT.NumOut())
+	err = callResultCheck(f.Type(), unwrap)
+	if err != nil {
+		return
+	}
+
+	rs, err := callRegularMulti(f, args, ellipsis)
+	if err != nil {
+		return
+	}
+	if len(rs) == 2 && !rs[1].IsNil() { // (T, error)
+		return nil, callReturnedError(rs[1].Interface().(error))
+	}
+	return MakeDataRegular(rs[0]), nil
+}
+
+// callRegularMulti calls f and returns all its results.
+// ellipsis true if last argument has ellipsis notation ("f(a,b,c...)").
+func callRegularMulti(f reflect.Value, args []Data, ellipsis bool) (rs []reflect.Value, err *intError) {
+	typedArgs := make([]reflect.Value, len(args)) // allocated by caller, so it may be placed on stack
+	err = callRegularArgs(f, args, ellipsis, typedArgs)
+	if err != nil {
+		return
+	}
+	if f.IsNil() {
+		return nil, nilDerefError() // like Go, arguments are checked before
+	}
+
+	defer func() {
+		if rec := recover(); rec != nil {
+			rs = nil
+			if p, ok := rec.(funcLitPanic); ok {
+				err = funcLitCallError(p.err)
+			} else {
+				err = callPanicError(rec, debug.Stack())
+			}
+		}
+	}()
+	if ellipsis {
+		rs = f.CallSlice(typedArgs)
+	} else {
+		rs = f.Call(typedArgs)
+	}
+	return
+}
+
+// callResultCheck checks what function of type fT returns single value.
+// If unwrap is true then function may also returns value and error.
+func callResultCheck(fT reflect.Type, unwrap bool) *intError {
+	switch n := fT.NumOut(); {
+	case n == 1:
+		return nil
+	case n == 2 && unwrap && fT.Out(1) == errorType:
+		return nil
+	default:
+		return callResultCountMismError(n)
 	}
+}
+
+// callRegularArgs checks what f can be called with args and converts args to types required by f storing them in typedArgs.
+// typedArgs must have the same length as args.
+// ellipsis true if last argument has ellipsis notation ("f(a,b,c...)").
+func callRegularArgs(f reflect.Value, args []Data, ellipsis bool, typedArgs []reflect.Value) *intError {
+	if f.Kind() != reflect.Func {
+		return callNonFuncError(MakeDataRegular(f))
+	}
+	fT := f.Type()
 
 	switch {
 	case fT.IsVariadic() && ellipsis:
-		return callRegularVariadicEllipsis(f, args)
+		return callArgsVariadicEllipsis(fT, args, typedArgs)
 	case fT.IsVariadic() && !ellipsis:
-		return callRegularVariadic(f, args)
+		return callArgsVariadic(fT, args, typedArgs)
 	case !fT.IsVariadic() && !ellipsis:
-		return callRegularNonVariadic(f, args)
+		return callArgsNonVariadic(fT, args, typedArgs)
 	}
-	return nil, callRegularWithEllipsisError()
+	return callRegularWithEllipsisError()
 }
 
-// f must be variadic func with single result (check must perform caller).
-func callRegularVariadicEllipsis(f reflect.Value, args []Data) (r Value, err *intError) {
-	fT := f.Type()
+// fT must be variadic func type (check must perform caller).
+func callArgsVariadicEllipsis(fT reflect.Type, args []Data, typedArgs []reflect.Value) *intError {
 	if len(args) != fT.NumIn() {
-		return nil, callArgsCountMismError(fT.NumIn(), len(args))
+		return callArgsCountMismError(fT.NumIn(), len(args))
 	}
 
 	// Prepare arguments
-	typedArgs := make([]reflect.Value, len(args))
 	for i := range args {
 		var ok bool
 		typedArgs[i], ok = args[i].Assign(fT.In(i))
 		if !ok {
-			return nil, callInvArgAtError(i, args[i], fT.In(i))
+			return callInvArgAtError(i, args[i], fT.In(i))
 		}
 	}
-
-	defer func() {
-		if rec := recover(); rec != nil {
-			r = nil
-			err = callPanicError(rec)
-		}
-	}()
-	rs := f.CallSlice(typedArgs)
-	return MakeDataRegular(rs[0]), nil
+	return nil
 }
 
-// f must be variadic func with single result (check must perform caller).
-func callRegularVariadic(f reflect.Value, args []Data) (r Value, err *intError) {
-	fT := f.Type()
+// fT must be variadic func type (check must perform caller).
+func callArgsVariadic(fT reflect.Type, args []Data, typedArgs []reflect.Value) *intError {
 	if len(args) < fT.NumIn()-1 {
-		return nil, callArgsCountMismError(fT.NumIn(), len(args)-1)
+		return callArgsCountMismError(fT.NumIn(), len(args)-1)
 	}
 
 	// Prepare arguments
-	typedArgs := make([]reflect.Value, len(args))
 	// non-variadic arguments
 	for i := 0; i < fT.NumIn()-1; i++ {
 		var ok bool
 		typedArgs[i], ok = args[i].Assign(fT.In(i))
 		if !ok {
-			return nil, callInvArgAtError(i, args[i], fT.In(i))
+			return callInvArgAtError(i, args[i], fT.In(i))
 		}
 	}
 	// variadic arguments
@@ -75,44 +129,26 @@ func callRegularVariadic(f reflect.Value, args []Data) (r Value, err *intError)
 		var ok bool
 		typedArgs[i], ok = args[i].Assign(variadicT)
 		if !ok {
-			return nil, callInvArgAtError(i, args[i], variadicT)
+			return callInvArgAtError(i, args[i], variadicT)
 		}
 	}
-
-	defer func() {
-		if rec := recover(); rec != nil {
-			r = nil
-			err = callPanicError(rec)
-		}
-	}()
-	rs := f.Call(typedArgs)
-	return MakeDataRegular(rs[0]), nil
+	return nil
 }
 
-// f must be non-variadic func with single result (check must perform caller).
-func callRegularNonVariadic(f reflect.Value, args []Data) (r Value, err *intError) {
-	// Check in/out arguments count
-	fT := f.Type()
+// fT must be non-variadic func type (check must perform caller).
+func callArgsNonVariadic(fT reflect.Type, args []Data, typedArgs []reflect.Value) *intError {
+	// Check in arguments count
 	if len(args) != fT.NumIn() {
-		return nil, callArgsCountMismError(fT.NumIn(), len(args))
+		return callArgsCountMismError(fT.NumIn(), len(args))
 	}
 
 	// Prepare arguments
-	typedArgs := make([]reflect.Value, len(args))
 	for i := range args {
 		var ok bool
 		typedArgs[i], ok = args[i].Assign(fT.In(i))
 		if !ok {
-			return nil, callInvArgAtError(i, args[i], fT.In(i))
+			return callInvArgAtError(i, args[i], fT.In(i))
 		}
 	}
-
-	defer func() {
-		if rec := recover(); rec != nil {
-			r = nil
-			err = callPanicError(rec)
-		}
-	}()
-	rs := f.Call(typedArgs)
-	return MakeDataRegular(rs[0]), nil
+	return nil
 }
@@ -0,0 +1,105 @@
+package eval
+
+import (
+	"go/ast"
+	"go/constant"
+	"reflect"
+)
+
+// TypeAndValue describes kind, type and (for constants) value of expression or its node computed by Expression.Check.
+type TypeAndValue struct {
+	Kind     ValueKind      // kind of value
+	DataKind DataKind       // kind of data, meaningful only if Kind is Datas
+	Type     reflect.Type   // type of data (nil for untyped data) or type itself if Kind is Type
+	Value    constant.Value // value of typed or untyped constant, nil for all other values
+}
+
+// IsConst reports whether described value is a typed or untyped constant.
+func (tv TypeAndValue) IsConst() bool { return tv.Value != nil }
+
+// CheckInfo is a result of Expression.Check.
+type CheckInfo struct {
+	TypeAndValue                           // result of expression
+	Types        map[ast.Expr]TypeAndValue // all nodes of expression (including expression itself and nodes inside function literals)
+}
+
+// Check checks expression against regular variables of given types like Compile does and returns information about expression and all its nodes.
+// types has the same format as ArgTypesFromTypes argument (package members are passed as "pkg.Name").
+// Check does not call any functions and does not receive from channels, so it is safe to call it if actual values of variables are unknown.
+// Values of non-constant nodes are not computed.
+func (e *Expression) Check(types map[string]reflect.Type) (r CheckInfo, err error) {
+	defer func() {
+		rec := recover()
+		if rec != nil {
+			r = CheckInfo{}
+			err = recoveredError(rec)
+		}
+	}()
+
+	state := &checkState{folded: make(map[ast.Expr]Value), nodes: make(map[ast.Expr]Value)}
+	_, v, err := e.checkSpec(ArgTypesFromTypes(types), state)
+	if err != nil {
+		return
+	}
+
+	_, folded := state.folded[e.e]
+	r.TypeAndValue = makeTypeAndValue(v, folded)
+	r.Types = make(map[ast.Expr]TypeAndValue, len(state.nodes))
+	for n, v := range state.nodes {
+		_, folded = state.folded[n]
+		r.Types[n] = makeTypeAndValue(v, folded)
+	}
+	return
+}
+
+// CheckAll checks expression against arguments described by spec like Compile does, but does not stop at first error.
+// Subexpressions which failed to check are treated as invalid, so errors caused only by them are not reported.
+// CheckAll returns nil if expression may be compiled, ErrorList of all errors sorted by position if it may not, and other error if spec itself is invalid.
+func (e *Expression) CheckAll(spec ArgTypes) (err error) {
+	defer func() {
+		rec := recover()
+		if rec != nil {
+			err = recoveredError(rec)
+		}
+	}()
+
+	state := &checkState{failed: make(map[ast.Expr]*posError)}
+	_, _, err = e.checkSpec(spec, state)
+	if err == nil || len(state.errs) == 0 {
+		return
+	}
+
+	list := make(ErrorList, len(state.errs))
+	for i := range state.errs {
+		list[i] = state.errs[i].error(e.fset).(Error)
+	}
+	list.Sort()
+	return list
+}
+
+// makeTypeAndValue describes value x of node.
+// folded reports whether value of node is computed at check time, so untyped boolean value is a constant.
+func makeTypeAndValue(x Value, folded bool) (r TypeAndValue) {
+	r.Kind = x.Kind()
+	switch r.Kind {
+	case Type:
+		r.Type = x.Type()
+	case Datas:
+		d := x.Data()
+		r.DataKind = d.Kind()
+		switch r.DataKind {
+		case Regular:
+			r.Type = d.Regular().Type()
+		case TypedConst:
+			r.Type = d.TypedConst().Type()
+			r.Value = d.TypedConst().Untyped()
+		case UntypedConst:
+			r.Value = d.UntypedConst()
+		case UntypedBool:
+			if folded {
+				r.Value = constant.MakeBool(d.UntypedBool())
+			}
+		}
+	}
+	return
+}
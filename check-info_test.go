@@ -0,0 +1,144 @@
+package eval
+
+import (
+	"errors"
+	"github.com/apaxa-go/helper/strconvh"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+func TestExpression_Check(t *testing.T) {
+	calls := 0
+	f := func(x int) int { calls++; return x }
+	types := map[string]reflect.Type{"x": reflect.TypeOf(0), "s": reflect.TypeOf(""), "f": reflect.TypeOf(f)}
+
+	type testElement struct {
+		expr     string
+		kind     DataKind
+		t        reflect.Type
+		value    constant.Value
+		errorMsg string
+	}
+	tests := []testElement{
+		{"f(x) + 1", Regular, reflect.TypeOf(0), nil, ""},
+		{"len(s) > 0", UntypedBool, nil, nil, ""},
+		{"1 < 2", UntypedBool, nil, constant.MakeBool(true), ""},
+		{"len([3]int{}) == 3 && true", UntypedBool, nil, constant.MakeBool(true), ""},
+		{"x > 0 || false", UntypedBool, nil, nil, ""},
+		{"int8(1) << 2", TypedConst, reflect.TypeOf(int8(0)), constant.MakeInt64(4), ""},
+		{"1 << 10", UntypedConst, nil, constant.MakeInt64(1024), ""},
+		{"len([3]int{})", TypedConst, reflect.TypeOf(0), constant.MakeInt64(3), ""},
+		{"s + x", 0, nil, nil, "expression:1:1: invalid operation: mismatched types string and int"},
+		{"y", 0, nil, nil, "expression:1:1: undefined: y"},
+	}
+	for _, test := range tests {
+		e, err := ParseString(test.expr, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := e.Check(types)
+		if test.errorMsg != "" {
+			if err == nil || err.Error() != test.errorMsg {
+				t.Errorf("%v: expect error %q, got %v", test.expr, test.errorMsg, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: %v", test.expr, err)
+			continue
+		}
+		if r.Kind != Datas || r.DataKind != test.kind || r.Type != test.t || r.IsConst() != (test.value != nil) || (test.value != nil && !constant.Compare(r.Value, token.EQL, test.value)) {
+			t.Errorf("%v: unexpected result %+v", test.expr, r.TypeAndValue)
+		}
+	}
+	if calls != 0 {
+		t.Error("Check must not call functions")
+	}
+
+	// Per-node information
+	e, err := ParseString("f(x) + len(s) * 2", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := e.Check(types)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := e.e.(*ast.BinaryExpr)
+	call := sum.X.(*ast.CallExpr)
+	mul := sum.Y.(*ast.BinaryExpr)
+	if tv := r.Types[call.Fun]; tv.Kind != Datas || tv.Type != reflect.TypeOf(f) {
+		t.Errorf("unexpected type of function %+v", tv)
+	}
+	if tv := r.Types[mul.X]; tv.Kind != Datas || tv.DataKind != Regular || tv.Type != reflect.TypeOf(0) {
+		t.Errorf("unexpected type of len(s) %+v", tv)
+	}
+	if tv := r.Types[mul.X.(*ast.CallExpr).Fun]; tv.Kind != BuiltInFunc {
+		t.Errorf("unexpected type of len %+v", tv)
+	}
+	if tv := r.Types[mul.Y]; tv.DataKind != UntypedConst || tv.Value.ExactString() != "2" {
+		t.Errorf("unexpected type of 2 %+v", tv)
+	}
+	if len(r.Types) != 9 {
+		t.Errorf("expect 9 nodes, got %v", len(r.Types))
+	}
+}
+
+func TestExpression_CheckAll(t *testing.T) {
+	type testElement struct {
+		expr string
+		errs []string
+	}
+
+	tests := []testElement{
+		{"a + b", nil},
+		{"x + y*z", []string{"1:1", "1:5", "1:7"}},
+		{"a + \"s\" + c", []string{"1:1", "1:11"}},
+		{"f(x, a+true, len(s))", []string{"1:1", "1:3", "1:6", "1:18"}},
+		{"[]int{x, 2: y}[z]", []string{"1:7", "1:13", "1:16"}},
+		{"func(i int) int { return i + x }(y)", []string{"1:30", "1:34"}},
+		{"-(x + 1)", []string{"1:3"}},
+	}
+
+	spec := ArgTypesFromTypes(map[string]reflect.Type{"a": reflect.TypeOf(0), "b": reflect.TypeOf(0)})
+	for _, test := range tests {
+		e, err := ParseString(test.expr, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = e.CheckAll(spec)
+		var r []string
+		if err != nil {
+			list, ok := err.(ErrorList)
+			if !ok {
+				t.Errorf("%v: expect ErrorList, got %T %v", test.expr, err, err)
+				continue
+			}
+			for _, err := range list {
+				r = append(r, strconvh.FormatInt(err.Pos.Line)+":"+strconvh.FormatInt(err.Pos.Column))
+			}
+		}
+		if !reflect.DeepEqual(r, test.errs) {
+			t.Errorf("%v: expect errors at %v, got %v (%v)", test.expr, test.errs, r, err)
+		}
+	}
+
+	// First error must be the same as reported by Compile
+	e, err := ParseString("x + y*z", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, compileErr := e.Compile(spec)
+	err = e.CheckAll(spec)
+	var list ErrorList
+	if !errors.As(err, &list) || list[0] != compileErr.(Error) || len(list) != 3 {
+		t.Errorf("expect first error %v, got %v", compileErr, err)
+	}
+	var first Error
+	if !errors.As(err, &first) || first != list[0] {
+		t.Errorf("errors.As must find first error")
+	}
+}
@@ -0,0 +1,321 @@
+package eval
+
+import (
+	"github.com/apaxa-go/helper/reflecth"
+	"go/ast"
+	"go/constant"
+	"reflect"
+)
+
+// checkState stores data collected while compiling expression.
+// While compiling expression is evaluated as usual, but regular variables are replaced with probes (see probe) and all operations which result depends on actual values of variables are checked without performing.
+type checkState struct {
+	folded  map[ast.Expr]Value     // nodes which values are known at compile time
+	nodes   map[ast.Expr]Value     // all checked nodes, nil if not required
+	runtime bool                   // true if some node in current subtree is computed only at run time
+	failed  map[ast.Expr]*posError // nodes which failed to check, nil if checking stops at first error
+	errs    []*posError            // errors which are not caused by errors in subexpressions, collected only if failed is not nil
+}
+
+func (expr *Expression) checking() bool { return expr != nil && expr.check != nil }
+
+func (expr *Expression) foldedValue(e ast.Expr) (r Value, ok bool) {
+	if expr == nil || expr.folded == nil {
+		return
+	}
+	r, ok = expr.folded[e]
+	return
+}
+
+func (expr *Expression) checkExpr(e ast.Expr, args Args) (r Value, err *posError) {
+	c := expr.check
+	if c.failed != nil {
+		if err, ok := c.failed[e]; ok {
+			return nil, err
+		}
+	}
+	outer := c.runtime
+	c.runtime = false
+	r, err = expr.astExprNode(e, args)
+	if err != nil {
+		if c.failed != nil {
+			expr.checkFailed(e, args, err)
+		}
+		return
+	}
+	if c.nodes != nil {
+		c.nodes[e] = r
+	}
+
+	switch {
+	case isArgIdent(e): // arguments are resolved at run time
+		c.runtime = outer || isVariable(r)
+	case isFoldable(r) && !(r.Kind() == Datas && r.Data().Kind() == UntypedBool && c.runtime):
+		if c.folded != nil {
+			c.folded[e] = r
+		}
+		c.runtime = outer
+	default:
+		c.runtime = true
+	}
+	return
+}
+
+// checkFailed records error err of node e if it is not caused by error in some subexpression of e.
+// Checking of e stops at first error, so subexpressions of e which may be not checked yet are checked independently to collect theirs errors too.
+func (expr *Expression) checkFailed(e ast.Expr, args Args, err *posError) {
+	c := expr.check
+	c.failed[e] = err
+	for _, sub := range subExprs(e) {
+		if sub != nil {
+			expr.astExpr(sub, args)
+		}
+	}
+
+	caused := false
+	ast.Inspect(e, func(n ast.Node) bool {
+		if sub, ok := n.(ast.Expr); ok && sub != e && !caused {
+			_, caused = c.failed[sub]
+		}
+		return !caused
+	})
+	if !caused {
+		c.errs = append(c.errs, err)
+	}
+}
+
+// subExprs returns subexpressions of e which may be checked independently of e.
+// Names (of fields, methods and parameters) and bodies of function literals are not returned.
+func subExprs(e ast.Expr) []ast.Expr {
+	switch v := e.(type) {
+	case *ast.ParenExpr:
+		return []ast.Expr{v.X}
+	case *ast.UnaryExpr:
+		return []ast.Expr{v.X}
+	case *ast.StarExpr:
+		return []ast.Expr{v.X}
+	case *ast.BinaryExpr:
+		return []ast.Expr{v.X, v.Y}
+	case *ast.SelectorExpr:
+		return []ast.Expr{v.X}
+	case *ast.IndexExpr:
+		return []ast.Expr{v.X, v.Index}
+	case *ast.IndexListExpr:
+		return append([]ast.Expr{v.X}, v.Indices...)
+	case *ast.SliceExpr:
+		return []ast.Expr{v.X, v.Low, v.High, v.Max}
+	case *ast.TypeAssertExpr:
+		return []ast.Expr{v.X, v.Type}
+	case *ast.CallExpr:
+		return append([]ast.Expr{v.Fun}, v.Args...)
+	case *ast.ArrayType:
+		if _, ok := v.Len.(*ast.Ellipsis); ok {
+			return []ast.Expr{v.Elt}
+		}
+		return []ast.Expr{v.Len, v.Elt}
+	case *ast.MapType:
+		return []ast.Expr{v.Key, v.Value}
+	case *ast.ChanType:
+		return []ast.Expr{v.Value}
+	case *ast.CompositeLit:
+		r := []ast.Expr{v.Type}
+		if t, ok := v.Type.(*ast.ArrayType); ok {
+			r = subExprs(t) // length of array may be "..."
+		}
+		keys := compositeLitKeys(v.Type)
+		for _, elt := range v.Elts {
+			if kv, ok := elt.(*ast.KeyValueExpr); ok {
+				if isLitKeyExpr(kv.Key, keys) {
+					r = append(r, kv.Key)
+				}
+				r = append(r, kv.Value)
+				continue
+			}
+			r = append(r, elt)
+		}
+		return r
+	default:
+		return nil
+	}
+}
+
+// staticExpr computes e like Compile does: without calling functions, receiving from channels and so on.
+// Resulting data has valid kind and type, but its value is meaningless if it is not a constant.
+func (expr *Expression) staticExpr(e ast.Expr, args Args) (r Data, err *posError) {
+	check := *expr
+	check.check = &checkState{}
+	return check.astExprAsData(e, args)
+}
+
+// skippedExpr checks operand e which is not evaluated (like right operand of "false && e").
+// Operations in e are never performed, so checking it does not consume MaxNodes and is not restricted by Policy.
+func (expr *Expression) skippedExpr(e ast.Expr, args Args) (r Data, err *posError) {
+	skip := *expr
+	if !skip.checking() {
+		skip.check = &checkState{}
+	}
+	skip.budget = nil
+	skip.opts.Policy = nil
+	return skip.astExprAsData(e, args)
+}
+
+// isArgIdent reports whether e is an identifier which is resolved via arguments.
+func isArgIdent(e ast.Expr) bool {
+	ident, ok := e.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	switch ident.Name {
+	case "true", "false", "nil":
+		return false
+	}
+	return !isBuiltInFunc(ident.Name) && !isBuiltInType(ident.Name)
+}
+
+// isVariable reports whether x is a data which value is known only at run time.
+func isVariable(x Value) bool {
+	if x.Kind() != Datas {
+		return false
+	}
+	k := x.Data().Kind()
+	return k == Regular || k == UntypedBool
+}
+
+// isFoldable reports whether x may be computed once at compile time and used for all evaluations.
+func isFoldable(x Value) bool {
+	return x.Kind() != Datas || x.Data().Kind() != Regular
+}
+
+// probe returns new addressable variable of type t used instead of variable with unknown value while compiling.
+// Numeric probes is 1 to prevent division by zero.
+func probe(t reflect.Type) reflect.Value {
+	r := reflect.New(t).Elem()
+	switch k := t.Kind(); {
+	case reflecth.IsInt(k):
+		r.SetInt(1)
+	case reflecth.IsUint(k):
+		r.SetUint(1)
+	case k == reflect.Float32 || k == reflect.Float64:
+		r.SetFloat(1)
+	case k == reflect.Complex64 || k == reflect.Complex128:
+		r.SetComplex(1)
+	}
+	return r
+}
+
+// probeData returns x with value of regular variable replaced by new probe.
+// While compiling values of regular variables are computed from probes, so they are meaningless.
+// Operations which validity depends on value of operand (division, shift, make, ...) use it to check only types of regular operands.
+func probeData(x Data) Data {
+	if x.Kind() != Regular {
+		return x
+	}
+	return MakeRegular(probe(x.Regular().Type()))
+}
+
+// argValue returns value of argument x as it should be used in expression.
+// While compiling regular variables are replaced with probes.
+func (expr *Expression) argValue(x Value) Value {
+	if expr.checking() && x.Kind() == Datas && x.Data().Kind() == Regular {
+		return MakeDataRegular(probe(x.Data().Regular().Type()))
+	}
+	return x
+}
+
+// callRegularCheck checks call of f without performing it.
+func callRegularCheck(f reflect.Value, args []Data, ellipsis, unwrap bool) (r Value, err *intError) {
+	if f.Kind() != reflect.Func {
+		return nil, callNonFuncError(MakeDataRegular(f))
+	}
+	err = callResultCheck(f.Type(), unwrap)
+	if err != nil {
+		return
+	}
+	err = callRegularArgs(f, args, ellipsis, make([]reflect.Value, len(args)))
+	if err != nil {
+		return
+	}
+	return MakeDataRegular(probe(f.Type().Out(0))), nil
+}
+
+// builtInMakeCheck checks call of built-in function make without allocating required memory.
+func builtInMakeCheck(v []Value, ellipsis bool) (r Value, err *intError) {
+	if ellipsis {
+		return nil, callBuiltInWithEllipsisError("make")
+	}
+	probes := make([]Value, len(v))
+	for i := range v {
+		probes[i] = v[i]
+		if v[i].Kind() == Datas {
+			probes[i] = MakeData(probeData(v[i].Data()))
+		}
+	}
+	t, n, m, err := builtInMakeArgs(probes)
+	if err != nil {
+		return
+	}
+	if n > m && m != -1 && v[1].Data().IsConst() && v[2].Data().IsConst() {
+		return nil, makeSliceMismArgsError(t)
+	}
+	if n > 0 {
+		n = 0
+	}
+	if m > 0 {
+		m = 0
+	}
+	return builtInMakeParsed(t, n, m)
+}
+
+// indexStatic checks index expression which result is unknown at compile time.
+func indexStatic(x Data, i Data) (r Value, err *intError) {
+	var xT reflect.Type
+	switch x.Kind() {
+	case Regular:
+		xT = x.Regular().Type()
+	case TypedConst:
+		xT = x.TypedConst().Type()
+	case UntypedConst:
+		if x.UntypedConst().Kind() != constant.String {
+			return nil, invIndexOpError(x, i)
+		}
+		xT = reflecth.TypeString()
+	default:
+		return nil, invIndexOpError(x, i)
+	}
+	if k := xT.Kind(); k != reflect.String && k != reflect.Array && k != reflect.Slice {
+		return nil, invIndexOpError(x, i)
+	}
+
+	iInt, ok := i.AsInt()
+	if !ok {
+		return nil, convertUnableError(reflect.TypeOf(int(0)), i)
+	}
+	if i.IsConst() && iInt < 0 {
+		return nil, indexOutOfRangeError(iInt)
+	}
+
+	if xT.Kind() == reflect.String {
+		return MakeDataRegular(probe(reflecth.TypeByte())), nil
+	}
+	return MakeDataRegular(probe(xT.Elem())), nil
+}
+
+// sliceStatic checks slice expression which result is unknown at compile time.
+func sliceStatic(x reflect.Value, slice3 bool) (r Value, err *intError) {
+	// resolve pointer to array
+	t := x.Type()
+	if t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Array {
+		t = t.Elem()
+	} else if t.Kind() == reflect.Array && !x.CanAddr() {
+		return nil, invSliceOpError(MakeRegular(x))
+	}
+
+	switch k := t.Kind(); {
+	case k == reflect.Array:
+		return MakeDataRegular(probe(reflect.SliceOf(t.Elem()))), nil
+	case k == reflect.Slice, k == reflect.String && !slice3:
+		return MakeDataRegular(probe(t)), nil
+	default:
+		return nil, invSliceOpError(MakeRegular(x))
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/constant"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// config describes what and how to generate.
+type config struct {
+	dir        string // directory of source package
+	importPath string // import path of source package, determined automatically if empty
+	name       string // package name used in keys, name of source package if empty
+	pkgName    string // name of package of generated file
+	funcName   string // name of generated function
+	tags       []string
+	include    *regexp.Regexp // nil means all identifiers
+	exclude    *regexp.Regexp // nil means no identifiers
+}
+
+func (cfg config) match(name string) bool {
+	if cfg.include != nil && !cfg.include.MatchString(name) {
+		return false
+	}
+	return cfg.exclude == nil || !cfg.exclude.MatchString(name)
+}
+
+// generate returns formatted source of file with function returning eval.Args for package described by cfg.
+func generate(cfg config) ([]byte, error) {
+	pkg, importPath, err := load(cfg)
+	if err != nil {
+		return nil, err
+	}
+	name := cfg.name
+	if name == "" {
+		name = pkg.Name()
+	}
+	alias := importAlias(pkg.Name())
+
+	var body bytes.Buffer
+	for _, ident := range pkg.Scope().Names() {
+		obj := pkg.Scope().Lookup(ident)
+		if !obj.Exported() || !cfg.match(ident) {
+			continue
+		}
+		v, ok := objectValue(obj, alias+"."+ident)
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&body, "\t\t%q: %v,\n", name+"."+ident, v)
+	}
+
+	var fn bytes.Buffer
+	fmt.Fprintf(&fn, "// %v returns arguments for package %v (%v).\n", cfg.funcName, name, importPath)
+	fmt.Fprintf(&fn, "func %v() eval.Args {\n\treturn eval.Args{\n%v\t}\n}\n", cfg.funcName, body.String())
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by evalgen; DO NOT EDIT.\n\npackage %v\n\nimport (\n", cfg.pkgName)
+	fmt.Fprintf(&src, "\t%q\n", "github.com/apaxa-go/eval")
+	for _, imp := range []struct{ name, path string }{
+		{"constanth", "github.com/apaxa-go/helper/goh/constanth"},
+		{"constant", "go/constant"},
+		{"token", "go/token"},
+		{"reflect", "reflect"},
+	} {
+		if bytes.Contains(fn.Bytes(), []byte(imp.name+".")) {
+			fmt.Fprintf(&src, "\t%q\n", imp.path)
+		}
+	}
+	fmt.Fprintf(&src, "\t%v %q\n)\n\n", alias, importPath)
+	src.Write(fn.Bytes())
+	return format.Source(src.Bytes())
+}
+
+// load parses and type-checks package described by cfg.
+func load(cfg config) (pkg *types.Package, importPath string, err error) {
+	ctx := build.Default
+	ctx.BuildTags = cfg.tags
+
+	bp, err := ctx.ImportDir(cfg.dir, 0)
+	if err != nil {
+		return nil, "", err
+	}
+	importPath = cfg.importPath
+	if importPath == "" {
+		importPath = bp.ImportPath
+	}
+	if importPath == "" || importPath == "." || strings.HasPrefix(importPath, "_") {
+		return nil, "", errors.New("unable to determine import path of package in " + cfg.dir + ", use -import flag")
+	}
+
+	fset := token.NewFileSet()
+	files, err := parseFiles(fset, bp)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Imported packages are also loaded from source with the same build tags.
+	conf := types.Config{Importer: newSourceImporter(&ctx, fset), FakeImportC: true}
+	pkg, err = conf.Check(importPath, fset, files, nil)
+	return
+}
+
+// parseFiles parses all Go files of package bp.
+func parseFiles(fset *token.FileSet, bp *build.Package) (files []*ast.File, err error) {
+	for _, name := range append(bp.GoFiles, bp.CgoFiles...) {
+		f, err := parser.ParseFile(fset, filepath.Join(bp.Dir, name), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return
+}
+
+// sourceImporter is a types.ImporterFrom which type-checks imported packages from source found via build context.
+// Unlike importer.ForCompiler(fset, "source", nil) it does not use build.Default, so build tags of context are respected without modification of global state.
+type sourceImporter struct {
+	ctx      *build.Context
+	fset     *token.FileSet
+	packages map[string]*types.Package // nil value means that package is being imported
+}
+
+func newSourceImporter(ctx *build.Context, fset *token.FileSet) *sourceImporter {
+	return &sourceImporter{ctx: ctx, fset: fset, packages: make(map[string]*types.Package)}
+}
+
+// Import implements types.Importer interface.
+func (imp *sourceImporter) Import(path string) (*types.Package, error) {
+	return imp.ImportFrom(path, ".", 0)
+}
+
+// ImportFrom implements types.ImporterFrom interface.
+func (imp *sourceImporter) ImportFrom(path, dir string, mode types.ImportMode) (*types.Package, error) {
+	if path == "unsafe" {
+		return types.Unsafe, nil
+	}
+	bp, err := imp.ctx.Import(path, dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	if pkg, ok := imp.packages[bp.ImportPath]; ok {
+		if pkg == nil {
+			return nil, errors.New("import cycle via " + bp.ImportPath)
+		}
+		return pkg, nil
+	}
+
+	imp.packages[bp.ImportPath] = nil
+	files, err := parseFiles(imp.fset, bp)
+	if err != nil {
+		return nil, err
+	}
+	conf := types.Config{Importer: imp, FakeImportC: true, IgnoreFuncBodies: true}
+	pkg, err := conf.Check(bp.ImportPath, imp.fset, files, nil)
+	if err != nil {
+		return nil, err
+	}
+	imp.packages[bp.ImportPath] = pkg
+	return pkg, nil
+}
+
+// importAlias returns name used for importing source package in generated file.
+func importAlias(name string) string {
+	switch name {
+	case "eval", "constanth", "constant", "token", "reflect":
+		return name + "pkg"
+	default:
+		return name
+	}
+}
+
+// objectValue returns source of expression which makes eval.Value for obj.
+// ref is the qualified reference to obj in generated file.
+// ok is false if obj can not be passed to eval.
+func objectValue(obj types.Object, ref string) (v string, ok bool) {
+	switch obj := obj.(type) {
+	case *types.Const:
+		c := constValue(obj.Val())
+		if basic, isBasic := obj.Type().(*types.Basic); isBasic && basic.Info()&types.IsUntyped != 0 {
+			// There are no untyped rune constants in eval, so typed constant is used to keep default type
+			if basic.Kind() == types.UntypedRune {
+				return "eval.MakeDataTypedConst(constanth.MustMakeTypedValue(" + c + ", reflect.TypeOf(rune(0))))", true
+			}
+			return "eval.MakeDataUntypedConst(" + c + ")", true
+		}
+		return "eval.MakeDataTypedConst(constanth.MustMakeTypedValue(" + c + ", reflect.TypeOf(" + ref + ")))", true
+	case *types.Var:
+		return "eval.MakeDataRegular(reflect.ValueOf(&" + ref + ").Elem())", true
+	case *types.Func:
+		if obj.Type().(*types.Signature).TypeParams().Len() != 0 {
+			return "", false
+		}
+		return "eval.MakeDataRegularInterface(" + ref + ")", true
+	case *types.TypeName:
+		if named, isNamed := obj.Type().(*types.Named); isNamed && named.TypeParams().Len() != 0 && !obj.IsAlias() {
+			return "", false
+		}
+		if iface, isIface := obj.Type().Underlying().(*types.Interface); isIface && !iface.IsMethodSet() {
+			return "", false // constraint interface may be used only as type parameter constraint
+		}
+		return "eval.MakeType(reflect.TypeOf((*" + ref + ")(nil)).Elem())", true
+	default:
+		return "", false
+	}
+}
+
+// constValue returns source of expression which makes constant.Value equal to x.
+func constValue(x constant.Value) string {
+	switch x.Kind() {
+	case constant.Bool:
+		return "constant.MakeBool(" + strconv.FormatBool(constant.BoolVal(x)) + ")"
+	case constant.String:
+		return "constant.MakeString(" + strconv.Quote(constant.StringVal(x)) + ")"
+	case constant.Int:
+		if i, exact := constant.Int64Val(x); exact {
+			return "constant.MakeInt64(" + strconv.FormatInt(i, 10) + ")"
+		}
+		if u, exact := constant.Uint64Val(x); exact {
+			return "constant.MakeUint64(" + strconv.FormatUint(u, 10) + ")"
+		}
+		if constant.Sign(x) < 0 {
+			return "constant.UnaryOp(token.SUB, " + constValue(constant.UnaryOp(token.SUB, x, 0)) + ", 0)"
+		}
+		return "constant.MakeFromLiteral(" + strconv.Quote(x.ExactString()) + ", token.INT, 0)"
+	case constant.Float:
+		// Division of integer constants produces exact float constant.
+		return "constant.BinaryOp(" + constValue(constant.Num(x)) + ", token.QUO, " + constValue(constant.Denom(x)) + ")"
+	case constant.Complex:
+		return "constant.BinaryOp(" + constValue(constant.Real(x)) + ", token.ADD, constant.MakeImag(" + constValue(constant.Imag(x)) + "))"
+	default:
+		return "constant.MakeUnknown()"
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"go/ast"
+	"go/build"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const sampleImport = "github.com/apaxa-go/eval/cmd/evalgen/testdata/sample"
+
+func TestGenerate(t *testing.T) {
+	cfg := config{dir: "testdata/sample", importPath: sampleImport, pkgName: "evalargs", funcName: "Args"}
+	src, err := generate(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	typeCheck(t, src, cfg.tags)
+	s := string(src)
+
+	expect := []string{
+		"// Code generated by evalgen; DO NOT EDIT.",
+		"package evalargs",
+		`sample "` + sampleImport + `"`,
+		"func Args() eval.Args {",
+		`"sample.Untyped":    eval.MakeDataUntypedConst(constant.MakeFromLiteral("1180591620717411303424", token.INT, 0)),`,
+		`"sample.Negative":   eval.MakeDataUntypedConst(constant.UnaryOp(token.SUB, constant.MakeFromLiteral("1180591620717411303424", token.INT, 0), 0)),`,
+		`"sample.Third":      eval.MakeDataUntypedConst(constant.BinaryOp(constant.MakeInt64(1), token.QUO, constant.MakeInt64(3))),`,
+		`"sample.Str":        eval.MakeDataUntypedConst(constant.MakeString("a\"b")),`,
+		`"sample.Typed":      eval.MakeDataTypedConst(constanth.MustMakeTypedValue(constant.MakeInt64(1000000000), reflect.TypeOf(sample.Typed))),`,
+		`"sample.TypedBool":  eval.MakeDataUntypedConst(constant.MakeBool(true)),`,
+		`"sample.Rune":       eval.MakeDataTypedConst(constanth.MustMakeTypedValue(constant.MakeInt64(120), reflect.TypeOf(rune(0)))),`,
+		`"sample.Var":        eval.MakeDataRegular(reflect.ValueOf(&sample.Var).Elem()),`,
+		`"sample.Struct":     eval.MakeType(reflect.TypeOf((*sample.Struct)(nil)).Elem()),`,
+		`"sample.Func":       eval.MakeDataRegularInterface(sample.Func),`,
+	}
+	for _, e := range expect {
+		if !strings.Contains(s, e) {
+			t.Errorf("expect %v in:\n%s", e, src)
+		}
+	}
+	for _, ident := range []string{"unexported", "Generic", "Number", "Tagged"} {
+		if strings.Contains(s, `"sample.`+ident+`"`) {
+			t.Errorf("unexpected %v in:\n%s", ident, src)
+		}
+	}
+
+	// Build tags & filters
+	cfg.tags = []string{"sampletag"}
+	cfg.include = regexp.MustCompile("^(Tagged|Func|Var)$")
+	cfg.exclude = regexp.MustCompile("^Var$")
+	cfg.name = "smpl"
+	src, err = generate(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	typeCheck(t, src, cfg.tags)
+	s = string(src)
+	if !strings.Contains(s, `"smpl.Tagged"`) || !strings.Contains(s, `"smpl.Func"`) || strings.Contains(s, `"smpl.Var"`) || strings.Contains(s, `"smpl.Str"`) {
+		t.Errorf("filters or build tags are not applied:\n%s", src)
+	}
+	if strings.Contains(s, `"go/constant"`) {
+		t.Errorf("unused import:\n%s", src)
+	}
+}
+
+// typeCheck checks that generated source compiles with given build tags.
+func typeCheck(t *testing.T, src []byte, tags []string) {
+	t.Helper()
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "args.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := build.Default
+	ctx.BuildTags = tags
+	conf := types.Config{Importer: newSourceImporter(&ctx, fset)}
+	if _, err = conf.Check("evalargs", fset, []*ast.File{f}, nil); err != nil {
+		t.Errorf("generated source does not compile: %v\n%s", err, src)
+	}
+}
+
+func TestConstValue(t *testing.T) {
+	// Generated float constants must be exact and of float kind.
+	x := constant.BinaryOp(constant.MakeInt64(4), token.QUO, constant.MakeInt64(2))
+	if x.Kind() != constant.Float {
+		t.Errorf("expect float constant, got %v", x.Kind())
+	}
+
+	tests := map[string]constant.Value{
+		"constant.MakeBool(false)":                  constant.MakeBool(false),
+		"constant.MakeInt64(-5)":                    constant.MakeInt64(-5),
+		"constant.MakeUint64(18446744073709551615)": constant.MakeUint64(1<<64 - 1),
+		"constant.BinaryOp(constant.MakeInt64(5), token.QUO, constant.MakeInt64(2))":                    constant.MakeFloat64(2.5),
+		"constant.BinaryOp(constant.MakeInt64(0), token.ADD, constant.MakeImag(constant.MakeInt64(1)))": constant.MakeImag(constant.MakeInt64(1)),
+	}
+	for want, x := range tests {
+		if got := constValue(x); got != want {
+			t.Errorf("expect %v, got %v", want, got)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Command evalgen generates Go source file with function returning eval.Args for all exported identifiers of Go package.
+//
+// Usage:
+//
+//	evalgen [flags] [dir]
+//
+// dir is the directory of source package (current directory by default).
+// Package is parsed and type-checked from source (go/parser & go/types), so no compiled packages and network access are required.
+//
+// Generated function returns eval.Args with keys in the "pkg.Name" notation:
+//   - functions are passed as regular variables,
+//   - variables are passed as addressable regular variables pointing to package variables (so expression sees actual values and may modify them),
+//   - typed and untyped constants are passed as typed and untyped constants with exact values (untyped rune constants are passed as typed constants of type rune),
+//   - types are passed via eval.MakeType.
+//
+// Generic functions, generic types and constraint interfaces are skipped.
+//
+// Flags:
+//
+//	-o file      output file (standard output by default)
+//	-package     name of package of generated file (default "evalargs")
+//	-func        name of generated function (default "Args")
+//	-import      import path of source package (determined automatically in GOPATH mode)
+//	-name        package name used in keys (name of source package by default)
+//	-tags        comma-separated list of build tags
+//	-include     regular expression, only matched identifiers are generated
+//	-exclude     regular expression, matched identifiers are not generated
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+)
+
+func main() {
+	var (
+		cfg     config
+		out     string
+		tags    string
+		include string
+		exclude string
+	)
+	flag.StringVar(&out, "o", "", "output file (standard output by default)")
+	flag.StringVar(&cfg.pkgName, "package", "evalargs", "name of package of generated file")
+	flag.StringVar(&cfg.funcName, "func", "Args", "name of generated function")
+	flag.StringVar(&cfg.importPath, "import", "", "import path of source package")
+	flag.StringVar(&cfg.name, "name", "", "package name used in keys (name of source package by default)")
+	flag.StringVar(&tags, "tags", "", "comma-separated list of build tags")
+	flag.StringVar(&include, "include", "", "regular expression, only matched identifiers are generated")
+	flag.StringVar(&exclude, "exclude", "", "regular expression, matched identifiers are not generated")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: evalgen [flags] [dir]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	switch flag.NArg() {
+	case 0:
+		cfg.dir = "."
+	case 1:
+		cfg.dir = flag.Arg(0)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if tags != "" {
+		cfg.tags = strings.Split(tags, ",")
+	}
+	var err error
+	if include != "" {
+		if cfg.include, err = regexp.Compile(include); err != nil {
+			fatal(err)
+		}
+	}
+	if exclude != "" {
+		if cfg.exclude, err = regexp.Compile(exclude); err != nil {
+			fatal(err)
+		}
+	}
+
+	src, err := generate(cfg)
+	if err != nil {
+		fatal(err)
+	}
+	if out == "" {
+		_, err = os.Stdout.Write(src)
+	} else {
+		err = ioutil.WriteFile(out, src, 0666)
+	}
+	if err != nil {
+		fatal(err)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "evalgen:", err)
+	os.Exit(1)
+}
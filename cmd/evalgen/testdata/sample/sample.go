@@ -0,0 +1,29 @@
+// Package sample is used for testing evalgen.
+package sample
+
+import "time"
+
+const (
+	Untyped            = 1 << 70
+	Negative           = -1 << 70
+	Third              = 1.0 / 3
+	Str                = "a\"b"
+	Typed              = time.Second
+	Complex            = 1 + 2i
+	unexported         = 1
+	TypedFloat float32 = 1.5
+	TypedBool          = Untyped > 0
+	Rune               = 'x'
+)
+
+var Var = 5
+
+type Struct struct{ A int }
+
+type Generic[T any] struct{ V T }
+
+type Number interface{ ~int | ~float64 }
+
+func Func(x int) int { return x + unexported }
+
+func GenericFunc[T any](x T) T { return x }
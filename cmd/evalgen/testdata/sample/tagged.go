@@ -0,0 +1,5 @@
+//go:build sampletag
+
+package sample
+
+func Tagged() {}
@@ -0,0 +1,120 @@
+// Command goeval is an interactive evaluator of Go expressions.
+//
+// Usage:
+//
+//	goeval [flags]
+//
+// goeval reads expressions from standard input line by line, evaluates each of them and prints the result (or error with its position).
+// Line in the form "name := expression" evaluates expression and stores result as variable name, so it can be used in the following lines.
+// Result is stored as is, so constants remain constants (with unlimited precision).
+//
+// Flags:
+//
+//	-stdlib      make packages from github.com/apaxa-go/eval/stdlib available (default true)
+//	-vars file   load variables from JSON object in file (may be repeated)
+//	-pkg path    evaluate expressions as if they are in package path
+//	-trace       print evaluation trace: each evaluated subexpression with its result
+//
+// Variables loaded from JSON have types which encoding/json uses for decoding into interface{} with the only exception: integer numbers have type int.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/apaxa-go/eval"
+	"github.com/apaxa-go/eval/stdlib"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// files is a flag.Value which may be specified multiple times.
+type files []string
+
+func (f *files) String() string { return strings.Join(*f, ",") }
+func (f *files) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
+
+func main() {
+	var (
+		useStdlib bool
+		vars      files
+		pkgPath   string
+		trace     bool
+	)
+	flag.BoolVar(&useStdlib, "stdlib", true, "make packages from github.com/apaxa-go/eval/stdlib available")
+	flag.Var(&vars, "vars", "load variables from JSON object in file (may be repeated)")
+	flag.StringVar(&pkgPath, "pkg", "", "evaluate expressions as if they are in package path")
+	flag.BoolVar(&trace, "trace", false, "print evaluation trace: each evaluated subexpression with its result")
+	flag.Parse()
+	if flag.NArg() != 0 {
+		fmt.Fprintln(os.Stderr, "usage: goeval [flags]")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	args := make(eval.Args)
+	if useStdlib {
+		args = stdlib.All()
+	}
+	for _, file := range vars {
+		fileArgs, err := loadVars(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "goeval:", err)
+			os.Exit(1)
+		}
+		args = stdlib.Merge(args, fileArgs)
+	}
+
+	s := newSession(args, pkgPath, os.Stdout)
+	s.trace = trace
+	s.run(os.Stdin)
+}
+
+// loadVars reads variables from JSON object stored in file.
+func loadVars(file string) (eval.Args, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var vars map[string]interface{}
+	if err = dec.Decode(&vars); err != nil {
+		return nil, errors.New(file + ": " + err.Error())
+	}
+	r := make(eval.Args, len(vars))
+	for name, v := range vars {
+		r[name] = eval.MakeDataRegularInterface(jsonValue(v))
+	}
+	return r, nil
+}
+
+// jsonValue replaces json.Number in decoded JSON value with int or float64.
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil && int64(int(i)) == i {
+			return int(i)
+		}
+		f, _ := v.Float64()
+		return f
+	case []interface{}:
+		for i := range v {
+			v[i] = jsonValue(v[i])
+		}
+		return v
+	case map[string]interface{}:
+		for k := range v {
+			v[k] = jsonValue(v[k])
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/apaxa-go/eval"
+	"github.com/apaxa-go/helper/goh/asth"
+	"go/ast"
+	"go/scanner"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const prompt = "> "
+
+// assignRE matches "name := expression", the first submatch is name.
+var assignRE = regexp.MustCompile(`^\s*([\pL_][\pL\pN_]*)\s*:=`)
+
+// session stores state of interactive evaluation.
+type session struct {
+	args    eval.Args
+	pkgPath string
+	out     io.Writer
+	trace   bool // print evaluation trace before result
+}
+
+func newSession(args eval.Args, pkgPath string, out io.Writer) *session {
+	if args == nil {
+		args = make(eval.Args)
+	}
+	return &session{args: args, pkgPath: pkgPath, out: out}
+}
+
+// run evaluates all lines from in.
+func (s *session) run(in io.Reader) {
+	sc := bufio.NewScanner(in)
+	fmt.Fprint(s.out, prompt)
+	for sc.Scan() {
+		s.line(sc.Text())
+		fmt.Fprint(s.out, prompt)
+	}
+	fmt.Fprintln(s.out)
+}
+
+// line evaluates single line and prints result.
+func (s *session) line(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+
+	// Split "name := expression"
+	var name string
+	src, offset := line, 0
+	if m := assignRE.FindStringSubmatchIndex(line); m != nil {
+		name = line[m[2]:m[3]]
+		src, offset = line[m[1]:], m[1]
+	}
+
+	r, err := s.eval(src)
+	if err != nil {
+		s.printError(line, offset, err)
+		return
+	}
+
+	if name != "" && name != "_" {
+		s.args[name] = r
+	}
+	fmt.Fprintln(s.out, valueString(r))
+}
+
+func (s *session) eval(src string) (eval.Value, error) {
+	expr, err := eval.ParseString(src, s.pkgPath)
+	if err != nil {
+		return nil, err
+	}
+	if !s.trace {
+		return expr.EvalRaw(s.args)
+	}
+	tracer := &treeTracer{src: src}
+	r, err := expr.WithOptions(eval.EvalOptions{Tracer: tracer}).EvalRaw(s.args)
+	for _, line := range tracer.lines {
+		fmt.Fprintln(s.out, line)
+	}
+	return r, err
+}
+
+// treeTracer collects evaluation trace as tree of nodes with theirs results (one node per line).
+type treeTracer struct {
+	src   string
+	lines []string
+	open  []int // indexes in lines of nodes being evaluated
+}
+
+func (t *treeTracer) Enter(n ast.Node, pos asth.Position) {
+	t.open = append(t.open, len(t.lines))
+	t.lines = append(t.lines, strings.Repeat("  ", len(t.open)-1)+t.src[pos.Offset:pos.EndOffset])
+}
+
+func (t *treeTracer) Exit(n ast.Node, pos asth.Position, r eval.Value, err error) {
+	i := t.open[len(t.open)-1]
+	t.open = t.open[:len(t.open)-1]
+	if err != nil {
+		t.lines[i] += " = error"
+		return
+	}
+	t.lines[i] += " = " + valueString(r)
+}
+
+// printError prints err with caret under its position in line.
+// offset is the offset of evaluated expression in line.
+func (s *session) printError(line string, offset int, err error) {
+	col, endCol := 0, 0
+	switch e := err.(type) {
+	case eval.Error:
+		col = e.Pos.Column
+		if e.Pos.EndLine == e.Pos.Line {
+			endCol = e.Pos.EndColumn
+		}
+	case scanner.ErrorList:
+		if len(e) > 0 {
+			col = e[0].Pos.Column
+			err = e[0]
+		}
+	}
+
+	if col > 0 {
+		fmt.Fprintln(s.out, strings.Repeat(" ", len(prompt))+line)
+		// Columns are byte based, but caret must be positioned by runes.
+		start := runeColumn(line, offset+col-1)
+		marker := "^"
+		if endCol > col+1 {
+			marker += strings.Repeat("~", runeColumn(line, offset+endCol-1)-start-1)
+		}
+		fmt.Fprintln(s.out, strings.Repeat(" ", len(prompt)+start)+marker)
+	}
+	fmt.Fprintln(s.out, "error:", err)
+}
+
+// runeColumn returns number of runes in line before byte offset i.
+// Offsets beyond the end of line are counted as single byte runes.
+func runeColumn(line string, i int) int {
+	if i <= len(line) {
+		return utf8.RuneCountInString(line[:i])
+	}
+	return utf8.RuneCountInString(line) + i - len(line)
+}
+
+// valueString returns human readable representation of v.
+func valueString(v eval.Value) string {
+	if v.Kind() == eval.Datas {
+		return v.Data().DeepString()
+	}
+	return v.String()
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"github.com/apaxa-go/eval"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSession(t *testing.T) {
+	var out bytes.Buffer
+	s := newSession(eval.ArgsFromInterfaces(eval.ArgsI{"a": 5}), "", &out)
+	s.run(strings.NewReader("x := a * 2\n\nx + 1\n_ := x\n" + `x + "s"` + "\n1 +\n"))
+
+	expect := `> 10 (type int)
+> > 11 (type int)
+> 10 (type int)
+>   x + "s"
+  ^~~~~~~
+error: expression:1:1: invalid operation: 10 (type int) + "s" (type untyped constant) (mismatched types int and untyped constant)
+>   1 +
+     ^
+error: expression:1:4: expected operand, found 'EOF'
+> 
+`
+	if out.String() != expect {
+		t.Errorf("expect:\n%v\ngot:\n%v", expect, out.String())
+	}
+	if _, ok := s.args["_"]; ok {
+		t.Error("blank identifier must not be stored")
+	}
+
+	// Caret position in assignment
+	out.Reset()
+	s.line("y := 1 + undefined")
+	if lines := strings.Split(out.String(), "\n"); len(lines) < 2 || lines[1] != strings.Repeat(" ", 11)+"^~~~~~~~~" {
+		t.Errorf("unexpected output:\n%v", out.String())
+	}
+
+	// Caret position after multibyte characters
+	out.Reset()
+	s.line(`"héllo" + undefinedé`)
+	if lines := strings.Split(out.String(), "\n"); len(lines) < 2 || lines[1] != strings.Repeat(" ", 12)+"^~~~~~~~~~" {
+		t.Errorf("unexpected output:\n%v", out.String())
+	}
+}
+
+func TestSession_Trace(t *testing.T) {
+	var out bytes.Buffer
+	s := newSession(eval.ArgsFromInterfaces(eval.ArgsI{"a": 5}), "", &out)
+	s.trace = true
+	s.run(strings.NewReader("a * (2 + 1)\na + b\n"))
+
+	expect := `> a * (2 + 1) = 15 (type int)
+  a = 5 (type int)
+  (2 + 1) = 3 (type untyped constant)
+    2 + 1 = 3 (type untyped constant)
+      2 = 2 (type untyped constant)
+      1 = 1 (type untyped constant)
+15 (type int)
+> a + b = error
+  a = 5 (type int)
+  b = error
+  a + b
+      ^
+error: expression:1:5: undefined: b
+> 
+`
+	if out.String() != expect {
+		t.Errorf("expect:\n%v\ngot:\n%v", expect, out.String())
+	}
+}
+
+func TestLoadVars(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goeval")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "vars.json")
+	if err = ioutil.WriteFile(file, []byte(`{"i": 1, "f": 1.5, "s": "str", "l": [1, 2]}`), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	args, err := loadVars(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string]interface{}{"i": 1, "f": 1.5, "s": "str"}
+	for name, v := range expect {
+		if got := args[name].Data().Regular().Interface(); got != v {
+			t.Errorf("%v: expect %v, got %v", name, v, got)
+		}
+	}
+	if l := args["l"].Data().Regular().Interface().([]interface{}); l[1] != 2 {
+		t.Errorf("expect %v, got %v", 2, l[1])
+	}
+
+	if err = ioutil.WriteFile(file, []byte(`[1]`), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = loadVars(file); err == nil {
+		t.Error("expect error")
+	}
+}
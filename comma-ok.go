@@ -0,0 +1,80 @@
+package eval
+
+import (
+	"github.com/apaxa-go/helper/reflecth"
+	"go/ast"
+	"go/token"
+	"reflect"
+)
+
+// astCommaOk evaluates e in comma-ok form ("v, ok := e").
+// Only map index expression, type assertion and receive from channel are allowed to be evaluated in such form (probably in parentheses).
+func (expr *Expression) astCommaOk(e ast.Expr, args Args) (r Value, ok bool, err *posError) {
+	switch v := e.(type) {
+	case *ast.ParenExpr:
+		return expr.astCommaOk(v.X, args)
+	case *ast.IndexExpr:
+		return expr.astIndexExprCommaOk(v, args)
+	case *ast.TypeAssertExpr:
+		return expr.astTypeAssertExprCommaOk(v, args)
+	case *ast.UnaryExpr:
+		if v.Op == token.ARROW {
+			return expr.astRecvCommaOk(v, args)
+		}
+	}
+	return nil, false, commaOkInvalError().pos(e)
+}
+
+func (expr *Expression) astIndexExprCommaOk(e *ast.IndexExpr, args Args) (r Value, ok bool, err *posError) {
+	x, err := expr.astExprAsData(e.X, args)
+	if err != nil {
+		return
+	}
+	if x.Kind() != Regular || x.Regular().Kind() != reflect.Map {
+		return nil, false, commaOkInvalError().pos(e)
+	}
+
+	i, err := expr.astExprAsData(e.Index, args)
+	if err != nil {
+		return
+	}
+
+	r, ok, intErr := indexMapCommaOk(x.Regular(), i)
+	err = intErr.pos(e)
+	return
+}
+
+func (expr *Expression) astTypeAssertExprCommaOk(e *ast.TypeAssertExpr, args Args) (r Value, ok bool, err *posError) {
+	x, t, err := expr.astTypeAssertExprOperands(e, args)
+	if err != nil {
+		return
+	}
+	rV, ok, _ := reflecth.TypeAssert(x, t)
+	if !ok {
+		rV = reflect.New(t).Elem() // Return zero value if assertion failed
+	}
+	return MakeDataRegular(rV), ok, nil
+}
+
+func (expr *Expression) astRecvCommaOk(e *ast.UnaryExpr, args Args) (r Value, ok bool, err *posError) {
+	x, err := expr.astExprAsData(e.X, args)
+	if err != nil {
+		return
+	}
+	if x.Kind() != Regular {
+		return nil, false, invUnaryOp(x, e.Op).pos(e)
+	}
+	xV := x.Regular()
+	if xV.Kind() != reflect.Chan || xV.Type().ChanDir()&reflect.RecvDir == 0 {
+		return nil, false, invUnaryOp(x, e.Op).pos(e)
+	}
+
+	rV, ok, intErr := expr.recv(xV)
+	if intErr != nil {
+		return nil, false, intErr.pos(e)
+	}
+	if !ok {
+		rV = reflect.New(xV.Type().Elem()).Elem() // Return zero value if channel is closed
+	}
+	return MakeDataRegular(rV), ok, nil
+}
@@ -0,0 +1,96 @@
+package eval
+
+import (
+	"context"
+	"reflect"
+)
+
+// EvalContext evaluates expression with given arguments args like EvalRaw does, but respects ctx.
+// ctx is checked before evaluating each node of expression and receiving from channel waits for ctx to be done too.
+// If ctx is done then evaluation stops with Error positioned at current node which Err field contains ctx.Err().
+// Function called from expression is never interrupted, so ctx should be passed to such functions via args if required.
+func (e *Expression) EvalContext(ctx context.Context, args Args) (r Value, err error) {
+	return e.withContext(ctx).EvalRaw(args)
+}
+
+// EvalCommaOkContext evaluates expression in comma-ok form like EvalCommaOk does, but respects ctx (see EvalContext).
+func (e *Expression) EvalCommaOkContext(ctx context.Context, args Args) (r Value, ok bool, err error) {
+	return e.withContext(ctx).EvalCommaOk(args)
+}
+
+// EvalMultiContext evaluates expression like EvalMulti does, but respects ctx (see EvalContext).
+func (e *Expression) EvalMultiContext(ctx context.Context, args Args) (r []Value, err error) {
+	return e.withContext(ctx).EvalMulti(args)
+}
+
+// EvalToDataContext evaluates expression like EvalToData does, but respects ctx (see EvalContext).
+func (e *Expression) EvalToDataContext(ctx context.Context, args Args) (r Data, err error) {
+	return e.withContext(ctx).EvalToData(args)
+}
+
+// EvalToRegularContext evaluates expression like EvalToRegular does, but respects ctx (see EvalContext).
+func (e *Expression) EvalToRegularContext(ctx context.Context, args Args) (r reflect.Value, err error) {
+	return e.withContext(ctx).EvalToRegular(args)
+}
+
+// EvalToInterfaceContext evaluates expression like EvalToInterface does, but respects ctx (see EvalContext).
+func (e *Expression) EvalToInterfaceContext(ctx context.Context, args Args) (r interface{}, err error) {
+	return e.withContext(ctx).EvalToInterface(args)
+}
+
+// EvalExplainContext evaluates expression like EvalExplain does, but respects ctx (see EvalContext).
+func (e *Expression) EvalExplainContext(ctx context.Context, args Args) (r *Explanation, err error) {
+	return e.withContext(ctx).EvalExplain(args)
+}
+
+// EvalContext evaluates compiled expression with given values of variables args like Eval does, but respects ctx.
+// See Expression.EvalContext for details.
+func (p *Program) EvalContext(ctx context.Context, args Args) (r Value, err error) {
+	p1 := *p
+	p1.expr = *p.expr.withContext(ctx)
+	return p1.Eval(args)
+}
+
+// withContext returns copy of expression which evaluation respects ctx.
+func (expr *Expression) withContext(ctx context.Context) *Expression {
+	if ctx.Done() == nil { // ctx is never canceled
+		return expr
+	}
+	r := *expr
+	r.ctx = ctx
+	return &r
+}
+
+// contextError returns error if evaluation should be stopped because of context.
+func (expr *Expression) contextError() *intError {
+	if expr == nil || expr.ctx == nil {
+		return nil
+	}
+	if err := expr.ctx.Err(); err != nil {
+		return contextDoneError(err)
+	}
+	return nil
+}
+
+// recv receives value from channel x respecting context (if any) and policy.
+// x must be a channel with receive direction.
+// ok is false if channel is closed.
+func (expr *Expression) recv(x reflect.Value) (r reflect.Value, ok bool, err *intError) {
+	if err = expr.allowRecv(x.Type()); err != nil {
+		return
+	}
+	if expr == nil || expr.ctx == nil {
+		r, ok = x.Recv()
+		return
+	}
+
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: x},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(expr.ctx.Done())},
+	}
+	i, r, ok := reflect.Select(cases)
+	if i == 1 {
+		return reflect.Value{}, false, contextDoneError(expr.ctx.Err())
+	}
+	return
+}
@@ -0,0 +1,97 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestExpression_EvalContext(t *testing.T) {
+	c := make(chan int, 1)
+	args := ArgsFromInterfaces(ArgsI{"c": c})
+
+	e, err := ParseString("1+<-c", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Value available
+	c <- 2
+	r, err := e.EvalContext(context.Background(), args)
+	if err != nil || r.Data().Regular().Interface() != 3 {
+		t.Errorf("expect %v, got %v %v", 3, r, err)
+	}
+
+	// Blocked receive
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = e.EvalContext(ctx, args)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expect %v, got %v", context.DeadlineExceeded, err)
+	}
+	if evalErr, ok := err.(Error); !ok || evalErr.Pos.Column != 3 {
+		t.Errorf("expect Error at column 3, got %#v", err)
+	}
+
+	// Comma-ok form
+	e2, err := ParseString("<-c", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = e2.EvalCommaOkContext(ctx, args)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expect %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	// Other forms
+	if _, err = e.EvalMultiContext(ctx, args); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("EvalMultiContext: expect %v, got %v", context.DeadlineExceeded, err)
+	}
+	if _, err = e.EvalToDataContext(ctx, args); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("EvalToDataContext: expect %v, got %v", context.DeadlineExceeded, err)
+	}
+	if _, err = e.EvalToRegularContext(ctx, args); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("EvalToRegularContext: expect %v, got %v", context.DeadlineExceeded, err)
+	}
+	if _, err = e.EvalToInterfaceContext(ctx, args); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("EvalToInterfaceContext: expect %v, got %v", context.DeadlineExceeded, err)
+	}
+	if x, err := e.EvalExplainContext(ctx, args); !errors.Is(err, context.DeadlineExceeded) || x == nil {
+		t.Errorf("EvalExplainContext: expect %v, got %v", context.DeadlineExceeded, err)
+	}
+	c <- 2
+	if r, err := e.EvalToInterfaceContext(context.Background(), args); err != nil || r != 3 {
+		t.Errorf("expect %v, got %v %v", 3, r, err)
+	}
+
+	// Already canceled
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	c <- 2
+	_, err = e.EvalContext(ctx, args)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expect %v, got %v", context.Canceled, err)
+	}
+	if len(c) != 1 {
+		t.Error("expect value is not received")
+	}
+	<-c
+
+	// Compiled
+	p, err := e.Compile(ArgTypes(args))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = p.EvalContext(ctx, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expect %v, got %v", context.DeadlineExceeded, err)
+	}
+	c <- 4
+	r, err = p.EvalContext(context.Background(), nil)
+	if err != nil || r.Data().Regular().Interface() != 5 {
+		t.Errorf("expect %v, got %v %v", 5, r, err)
+	}
+}
@@ -9,24 +9,29 @@ import (
 )
 
 func binaryOpRegular(x reflect.Value, op token.Token, y reflect.Value) (r Data, err *intError) {
+	if (op == token.QUO || op == token.REM) && isZeroInt(y) {
+		return nil, divideByZeroError()
+	}
 	if rV, errE := reflecth.BinaryOp(x, op, y); errE == nil {
 		r = regData(rV)
 	} else {
-		err = toIntError(errE)
+		err = opError(errE, x.Type(), y.Type())
 	}
 	return
 }
 func compareOpRegular(x reflect.Value, op token.Token, y reflect.Value) (r bool, err *intError) {
 	var errE error
 	r, errE = reflecth.CompareOp(x, op, y)
-	err = toIntError(errE)
+	if errE != nil {
+		err = opError(errE, x.Type(), y.Type())
+	}
 	return
 }
 func shiftOpRegular(x reflect.Value, op token.Token, s uint) (r Data, err *intError) {
 	if rV, errE := reflecth.ShiftOp(x, op, s); errE == nil {
 		r = regData(rV)
 	} else {
-		err = toIntError(errE)
+		err = toIntError(errE).setCode(InvalidOperation)
 	}
 	return
 }
@@ -34,7 +39,7 @@ func unaryOpRegular(op token.Token, y reflect.Value) (r Data, err *intError) {
 	if rV, errE := reflecth.UnaryOp(op, y); errE == nil {
 		r = regData(rV)
 	} else {
-		err = toIntError(errE)
+		err = toIntError(errE).setCode(InvalidOperation)
 	}
 	return
 }
@@ -42,55 +47,129 @@ func binaryOpTypedConst(x constanth.TypedValue, op token.Token, y constanth.Type
 	if rTC, errE := constanth.BinaryOpTyped(x, op, y); errE == nil {
 		r = typedConstData(rTC)
 	} else {
-		err = toIntError(errE)
+		switch {
+		case x.Type() != y.Type():
+			err = opError(errE, x.Type(), y.Type())
+		case (op == token.QUO || op == token.REM) && isZeroConst(y.Untyped()):
+			err = toIntError(errE).setCode(DivisionByZero)
+		default:
+			rC, errC := constanth.BinaryOp(exactValue(x), op, exactValue(y))
+			err = typedConstOpError(errE, rC, errC, x.Type())
+		}
 	}
 	return
 }
 func compareOpTypedConst(x constanth.TypedValue, op token.Token, y constanth.TypedValue) (r bool, err *intError) {
 	var errE error
 	r, errE = constanth.CompareOpTyped(x, op, y)
-	err = toIntError(errE)
+	if errE != nil {
+		err = opError(errE, x.Type(), y.Type())
+	}
 	return
 }
 func shiftOpTypedConst(x constanth.TypedValue, op token.Token, s uint) (r constanth.TypedValue, err *intError) {
 	var errE error
 	r, errE = constanth.ShiftOpTyped(x, op, s)
-	err = toIntError(errE)
+	if errE != nil {
+		rC, errC := constanth.ShiftOp(x.Untyped(), op, s)
+		err = typedConstOpError(errE, rC, errC, x.Type())
+	}
 	return
 }
 func unaryOpTypedConst(op token.Token, y constanth.TypedValue, prec uint) (r Data, err *intError) {
 	if rTC, errE := constanth.UnaryOpTyped(op, y, prec); errE == nil {
 		r = typedConstData(rTC)
+	} else if op == token.SUB {
+		rC, errC := constanth.UnaryOp(op, exactValue(y), prec)
+		err = typedConstOpError(errE, rC, errC, y.Type())
 	} else {
-		err = toIntError(errE)
+		err = toIntError(errE).setCode(InvalidOperation)
 	}
 	return
 }
 func binaryOpUntypedConst(x constant.Value, op token.Token, y constant.Value) (r Data, err *intError) {
 	if rC, errE := constanth.BinaryOp(x, op, y); errE == nil {
 		r = untypedConstData{rC}
+	} else if (op == token.QUO || op == token.REM) && isZeroConst(y) {
+		err = toIntError(errE).setCode(DivisionByZero)
 	} else {
-		err = toIntError(errE)
+		err = toIntError(errE).setCode(InvalidOperation)
 	}
 	return
 }
 func compareOpUntypedConst(x constant.Value, op token.Token, y constant.Value) (r bool, err *intError) {
 	var errE error
 	r, errE = constanth.CompareOp(x, op, y)
-	err = toIntError(errE)
+	err = toIntError(errE).setCode(InvalidOperation)
 	return
 }
 func shiftOpUntypedConst(x constant.Value, op token.Token, s uint) (r constant.Value, err *intError) {
 	var errE error
 	r, errE = constanth.ShiftOp(x, op, s)
-	err = toIntError(errE)
+	err = toIntError(errE).setCode(InvalidOperation)
 	return
 }
 func unaryOpUntypedConst(op token.Token, y constant.Value, prec uint) (r Data, err *intError) {
 	if rC, errE := constanth.UnaryOp(op, y, prec); errE == nil {
 		r = untypedConstData{rC}
 	} else {
-		err = toIntError(errE)
+		err = toIntError(errE).setCode(InvalidOperation)
 	}
 	return
 }
+
+// opError returns error of operation on operands of types x and y which fails with errE.
+// Operation on operands of different types fails due to types mismatch.
+func opError(errE error, x, y reflect.Type) *intError {
+	if x != y {
+		return toIntError(errE).setCode(TypeMismatch).setTypes(x, y)
+	}
+	return toIntError(errE).setCode(InvalidOperation)
+}
+
+// typedConstOpError returns error of operation on typed constants of type t which fails with errE.
+// rC and errC are the result of the same operation on exact (untyped) values.
+// If exact result is valid, but is not representable by t, then operation fails due to overflow.
+func typedConstOpError(errE error, rC constant.Value, errC error, t reflect.Type) *intError {
+	if errC == nil {
+		if _, ok := constanth.MakeTypedValue(rC, t); !ok {
+			return toIntError(errE).setCode(ConstOverflow).setTypes(t, nil)
+		}
+	}
+	return toIntError(errE).setCode(InvalidOperation)
+}
+
+// exactValue returns untyped value of x suitable to compute exact result of operation on constants of type of x.
+// Values of floating-point and complex types are converted to float and complex values, so division of them is not truncated.
+func exactValue(x constanth.TypedValue) constant.Value {
+	switch k := x.Type().Kind(); {
+	case reflecth.IsFloat(k):
+		return constant.ToFloat(x.Untyped())
+	case reflecth.IsComplex(k):
+		return constant.ToComplex(x.Untyped())
+	default:
+		return x.Untyped()
+	}
+}
+
+// isZeroConst reports whether x is a numeric constant zero.
+func isZeroConst(x constant.Value) bool {
+	switch x.Kind() {
+	case constant.Int, constant.Float, constant.Complex:
+		return constant.Sign(x) == 0
+	default:
+		return false
+	}
+}
+
+// isZeroInt reports whether x is an integer zero.
+func isZeroInt(x reflect.Value) bool {
+	switch k := x.Kind(); {
+	case reflecth.IsInt(k):
+		return x.Int() == 0
+	case reflecth.IsUint(k):
+		return x.Uint() == 0
+	default:
+		return false
+	}
+}
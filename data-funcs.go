@@ -37,6 +37,11 @@ func binaryOp(x Data, op token.Token, y Data) (r Data, err *intError) {
 			}
 		}
 
+		// Integer division by constant zero is reported at compile time (like Go does)
+		if (op == token.QUO || op == token.REM) && yK != Regular && isZeroInt(yV) {
+			return nil, divByConstZeroError()
+		}
+
 		// Calc
 		r, err = binaryOpRegular(xV, op, yV)
 	case xK == TypedConst && yK == TypedConst, xK == TypedConst && yK == UntypedConst, xK == UntypedConst && yK == TypedConst: // At least one args is typed constant
@@ -70,6 +75,88 @@ func binaryOp(x Data, op token.Token, y Data) (r Data, err *intError) {
 	return
 }
 
+// isLogicalOp reports whether op is conditional logical operator ("&&" or "||").
+func isLogicalOp(op token.Token) bool { return op == token.LAND || op == token.LOR }
+
+// isLogicalOpDetermined reports whether result of conditional logical operation op is determined by left operand x.
+// In such case right operand must not be evaluated.
+func isLogicalOpDetermined(x Data, op token.Token) bool {
+	xB, ok := boolVal(x)
+	return ok && xB == (op == token.LOR)
+}
+
+// boolVal returns boolean value of x if x is a boolean.
+func boolVal(x Data) (r bool, ok bool) {
+	switch x.Kind() {
+	case Regular:
+		xV := x.Regular()
+		if xV.Kind() != reflect.Bool {
+			return false, false
+		}
+		return xV.Bool(), true
+	case TypedConst:
+		return constanth.BoolVal(x.TypedConst().Untyped())
+	case UntypedConst:
+		return constanth.BoolVal(x.UntypedConst())
+	case UntypedBool:
+		return x.UntypedBool(), true
+	default:
+		return false, false
+	}
+}
+
+// logicalOp performs conditional logical operation.
+// Unlike binaryOp it accepts untyped boolean variables.
+func logicalOp(x Data, op token.Token, y Data) (r Data, err *intError) {
+	xK, yK := x.Kind(), y.Kind()
+	if xK != UntypedBool && yK != UntypedBool {
+		return binaryOp(x, op, y)
+	}
+
+	// Other operand determines type of result
+	other := x
+	if xK == UntypedBool {
+		other = y
+	}
+
+	var t reflect.Type // nil if result is untyped boolean
+	switch other.Kind() {
+	case UntypedBool:
+	case UntypedConst:
+		if other.UntypedConst().Kind() != constant.Bool {
+			return nil, invBinOpTypesMismError(x, op, y)
+		}
+	case Regular:
+		t = other.Regular().Type()
+	case TypedConst:
+		t = other.TypedConst().Type()
+	default:
+		return nil, invBinOpTypesInvalError(x, op, y)
+	}
+	if t != nil && t.Kind() != reflect.Bool {
+		return nil, invBinOpTypesMismError(x, op, y)
+	}
+
+	xB, _ := boolVal(x)
+	yB, _ := boolVal(y)
+	var rB bool
+	switch op {
+	case token.LAND:
+		rB = xB && yB
+	case token.LOR:
+		rB = xB || yB
+	default:
+		return nil, invBinOpUnknOpError(x, op, y)
+	}
+
+	if t == nil {
+		return untypedBoolData(rB), nil
+	}
+	rV := reflect.New(t).Elem()
+	rV.SetBool(rB)
+	return regData(rV), nil
+}
+
 func compareOpWithNil(x Data, op token.Token) (r Data, err *intError) {
 	var equality bool // true if op == "=="
 	switch op {
@@ -1,7 +1,8 @@
 // Package eval implements evaluation of GoLang expression at runtime.
 //
 // Requirements for expression:
-// 	1. expression itself and all of subexpression must return exactly one value,
+// 	1. expression itself and all of subexpression must return exactly one value
+// 	(with EvalOptions.UnwrapErrors function may also return value and error; EvalMulti allows top level call to return any number of values),
 // 	2. see Bugs section for other requirements/restrictions.
 //
 // What does supported:
@@ -31,8 +32,11 @@
 // 	15. short and full slice expression ("a[1:2:3]")
 // 	16. composite literal ("MyStruct{nil,2,a}")
 // 	17. type conversion ("MyInt(int(1))")
-// 	18. type assertion ("a.(int)"; only single result form)
+// 	18. type assertion ("a.(int)")
 // 	19. receiving from channel ("<-c")
+// 	20. function literal ("func(x int) int { return x*2 }"; body must consist of single return statement)
+// 	21. generic function and type instantiation ("Map[int, string](s, f)", "Map(s, f)", "Pair[string, int]{}"; instantiations must be provided via MakeGenericFunc and MakeGenericType)
+// Map index expression, type assertion and receiving from channel may also be evaluated in comma-ok form via EvalCommaOk.
 //
 // Predefined types (no need to pass it via args):
 // 	1. bool
@@ -53,6 +57,7 @@
 // 	6. new
 // 	7. make
 // 	8. append
+// Additional built-in functions may be registered via RegisterBuiltInFunc.
 //
 // Simple example:
 //	src:="int8(1*(1+2))"
@@ -135,9 +140,24 @@
 // 	1. EvalRaw - the most flexible, but the hardest to use,
 // 	2. EvalToData,
 // 	3. EvalToRegular,
-// 	4. EvalToInterface - the least flexible, but the easiest to use.
+// 	4. EvalToInterface - the least flexible, but the easiest to use,
+// 	5. EvalCommaOk - evaluates expression in comma-ok form,
+// 	6. EvalMulti - returns all results of top level function call,
+// 	7. EvalContext - evaluation which may be canceled via context (all other methods also have variants with context: EvalMultiContext, ...),
+// 	8. EvalExplain - explains result: returns tree of evaluated subexpressions with theirs values.
+// Evaluation may be customized via WithOptions (for example, resources used by evaluation of untrusted expression may be limited and access to arguments may be restricted via Policy).
 // In most cases EvalToInterface should be enough and it is easy to use.
 //
+// If the same expression should be evaluated many times with different values of the same variables it may be compiled via Expression.Compile.
+// Compile checks expression against types of variables and computes all constant subexpressions once, resulting Program evaluates only the rest.
+// Both Expression and Program may be encoded to JSON (for example, to be cached or distributed to other processes), program is restored via LoadProgram which verifies that it is loaded with the same arguments as it was compiled with and compiles it again (result of compilation is not encoded).
+// Expression.CheckAll performs the same checks as Compile, but reports all errors (as ErrorList) instead of the first one.
+// Each Error has stable machine-readable Code (which may be checked via errors.Is) and structured details (identifier, expected and actual types, argument index).
+// Panics in called functions are reported as Error wrapping PanicError (with original panic value and stack trace), or propagated to caller if EvalOptions.Panics is PanicRepanic.
+// Intermediate results of evaluation may be observed via EvalOptions.Tracer which is called on enter and exit of each evaluated node.
+//
+// Small computations which does not fit into single expression may be written as Script (parsed via ParseScript): list of statements (variable declarations, assignments, if, for, ...) ending with return statement.
+//
 // Evaluation performance:
 //	// Parse expression from string
 //	BenchmarkDocParse-8      200000     9118 ns/op    3800 B/op    106 allocs/op
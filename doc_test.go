@@ -124,3 +124,29 @@ func BenchmarkDocGoEval(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkDocProgramEval(b *testing.B) {
+	expr, err := ParseString(exampleBenchSrc, "")
+	if err != nil {
+		b.Fatal(err)
+	}
+	p, err := expr.Compile(ArgTypes{
+		"exampleString": MakeTypeInterface(exampleString("")),
+		"fmt.Sprint":    MakeDataRegularInterface(fmt.Sprint),
+		"math.MaxInt32": MakeDataUntypedConst(constanth.MakeUint(math.MaxInt32)),
+		"exampleStruct": MakeTypeInterface(exampleStruct{}),
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for i := 0; i < b.N; i++ {
+		r, err := p.Eval(nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if r.Data().Regular().Interface() != exampleBenchResult {
+			b.Fatalf("expect %v, got %v", exampleBenchResult, r)
+		}
+	}
+}
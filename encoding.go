@@ -0,0 +1,582 @@
+package eval
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"go/ast"
+	"go/token"
+	"reflect"
+	"strconv"
+)
+
+// EncodingVersion is the version of format produced by Expression.MarshalJSON and Program.MarshalJSON.
+// Data encoded with other version can not be decoded.
+const EncodingVersion = 3
+
+// ArgMismatchError is returned by LoadProgram if arguments passed at load time differ from arguments used at compile time.
+type ArgMismatchError struct {
+	Ident    string // identifier of argument ("x" or "pkg.X")
+	Compiled string // description of argument at compile time
+	Loaded   string // description of argument at load time, empty if argument is missing
+}
+
+// Error implements standard error interface.
+func (err ArgMismatchError) Error() string {
+	if err.Loaded == "" {
+		return "argument " + err.Ident + " (" + err.Compiled + ") is missing"
+	}
+	return "argument " + err.Ident + " mismatch: compiled with " + err.Compiled + ", loaded with " + err.Loaded
+}
+
+// encodedExpr is a top level structure of encoded expression.
+type encodedExpr struct {
+	Version int               `json:"version"`
+	PkgPath string            `json:"pkgPath,omitempty"`
+	File    *encodedFile      `json:"file,omitempty"`
+	Expr    json.RawMessage   `json:"expr"`
+	Args    map[string]string `json:"args,omitempty"` // descriptions of arguments used by compiled expression
+}
+
+// encodedFile describes source of expression, it is used to restore positions in errors.
+type encodedFile struct {
+	Name  string `json:"name"`
+	Size  int    `json:"size"`
+	Lines []int  `json:"lines"`
+}
+
+// MarshalJSON implements json.Marshaler.
+// Result contains AST of expression (with positions), package path and name of source file, so decoded expression reports errors in the same way as original one.
+// Options (see WithOptions) are not encoded.
+func (e *Expression) MarshalJSON() ([]byte, error) {
+	r, err := e.encode()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(r)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+// It decodes expression encoded by Expression.MarshalJSON or Program.MarshalJSON (in the last case expression is decoded as not compiled).
+func (e *Expression) UnmarshalJSON(data []byte) error {
+	var enc encodedExpr
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return err
+	}
+	r, err := enc.decode()
+	if err != nil {
+		return err
+	}
+	*e = *r
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+// Result is the same as for source expression, but also contains description of all arguments used by expression at compile time.
+// Such descriptions are verified by LoadProgram.
+// Result of compilation itself is not encoded.
+func (p *Program) MarshalJSON() ([]byte, error) {
+	r, err := p.expr.encode()
+	if err != nil {
+		return nil, err
+	}
+	r.Args = make(map[string]string)
+	for _, ident := range p.expr.usedIdents(p.expr.spec) {
+		r.Args[ident], _ = lookupArgDescription(p.expr.spec, ident)
+	}
+	return json.Marshal(r)
+}
+
+// LoadProgram decodes program encoded by Program.MarshalJSON and compiles it against spec.
+// All arguments used by expression must be passed in spec with the same types (and values for constants) as at compile time, otherwise ArgMismatchError is returned.
+// Encoded program contains only AST of expression and descriptions of arguments, so LoadProgram does not save compilation time:
+// expression is compiled again via Expression.Compile and the only benefit is verification that arguments are the same as at compile time.
+func LoadProgram(data []byte, spec ArgTypes) (p *Program, err error) {
+	var enc encodedExpr
+	if err = json.Unmarshal(data, &enc); err != nil {
+		return
+	}
+	if enc.Args == nil {
+		return nil, errors.New("encoded expression is not compiled")
+	}
+	e, err := enc.decode()
+	if err != nil {
+		return
+	}
+
+	args := make(Args, len(spec))
+	for ident, arg := range spec {
+		args[ident] = arg
+	}
+	if err = args.validate(); err != nil {
+		return
+	}
+	if err = args.normalize(); err != nil {
+		return
+	}
+	for ident, compiled := range enc.Args {
+		if loaded, _ := lookupArgDescription(args, ident); loaded != compiled {
+			return nil, ArgMismatchError{ident, compiled, loaded}
+		}
+	}
+
+	return e.Compile(spec)
+}
+
+// fileLines returns offsets of first characters of lines of f (like token.File.Lines which is not available before Go 1.21).
+func fileLines(f *token.File) (r []int) {
+	for line := 1; line <= f.LineCount(); line++ {
+		r = append(r, f.Offset(f.LineStart(line)))
+	}
+	return
+}
+
+func (e *Expression) encode() (*encodedExpr, error) {
+	r := &encodedExpr{Version: EncodingVersion, PkgPath: e.pkgPath}
+	base, size := 0, 0
+	if f := e.fset.File(e.e.Pos()); f != nil {
+		r.File = &encodedFile{Name: f.Name(), Size: f.Size(), Lines: fileLines(f)}
+		base, size = f.Base(), f.Size()
+	}
+
+	node, err := encodeNode(reflect.ValueOf(&e.e).Elem(), base, size)
+	if err != nil {
+		return nil, err
+	}
+	r.Expr, err = json.Marshal(node)
+	return r, err
+}
+
+func (enc *encodedExpr) decode() (*Expression, error) {
+	if enc.Version != EncodingVersion {
+		return nil, errors.New("unsupported encoding version " + strconv.Itoa(enc.Version))
+	}
+
+	r := &Expression{fset: token.NewFileSet(), pkgPath: enc.PkgPath}
+	base, size := 0, 0
+	if enc.File != nil {
+		f := r.fset.AddFile(enc.File.Name, -1, enc.File.Size)
+		if !f.SetLines(enc.File.Lines) {
+			return nil, errors.New("invalid line table of encoded expression")
+		}
+		base, size = f.Base(), f.Size()
+	}
+
+	v := reflect.New(reflect.TypeOf((*ast.Expr)(nil)).Elem()).Elem()
+	if err := decodeNode(enc.Expr, v, base, size); err != nil {
+		return nil, err
+	}
+	if v.IsNil() {
+		return nil, errors.New("encoded expression is empty")
+	}
+	r.e = v.Interface().(ast.Expr)
+	return r, nil
+}
+
+// nodeNames maps AST nodes which may be encoded (all nodes which may appear in expression) to their names in encoding.
+var nodeNames = map[reflect.Type]string{
+	reflect.TypeOf(&ast.Ident{}):          "Ident",
+	reflect.TypeOf(&ast.BasicLit{}):       "BasicLit",
+	reflect.TypeOf(&ast.CompositeLit{}):   "CompositeLit",
+	reflect.TypeOf(&ast.FuncLit{}):        "FuncLit",
+	reflect.TypeOf(&ast.ParenExpr{}):      "ParenExpr",
+	reflect.TypeOf(&ast.SelectorExpr{}):   "SelectorExpr",
+	reflect.TypeOf(&ast.IndexExpr{}):      "IndexExpr",
+	reflect.TypeOf(&ast.IndexListExpr{}):  "IndexListExpr",
+	reflect.TypeOf(&ast.SliceExpr{}):      "SliceExpr",
+	reflect.TypeOf(&ast.TypeAssertExpr{}): "TypeAssertExpr",
+	reflect.TypeOf(&ast.CallExpr{}):       "CallExpr",
+	reflect.TypeOf(&ast.StarExpr{}):       "StarExpr",
+	reflect.TypeOf(&ast.UnaryExpr{}):      "UnaryExpr",
+	reflect.TypeOf(&ast.BinaryExpr{}):     "BinaryExpr",
+	reflect.TypeOf(&ast.KeyValueExpr{}):   "KeyValueExpr",
+	reflect.TypeOf(&ast.Ellipsis{}):       "Ellipsis",
+	reflect.TypeOf(&ast.ArrayType{}):      "ArrayType",
+	reflect.TypeOf(&ast.StructType{}):     "StructType",
+	reflect.TypeOf(&ast.FuncType{}):       "FuncType",
+	reflect.TypeOf(&ast.InterfaceType{}):  "InterfaceType",
+	reflect.TypeOf(&ast.MapType{}):        "MapType",
+	reflect.TypeOf(&ast.ChanType{}):       "ChanType",
+	reflect.TypeOf(&ast.Field{}):          "Field",
+	reflect.TypeOf(&ast.FieldList{}):      "FieldList",
+	reflect.TypeOf(&ast.BlockStmt{}):      "BlockStmt",
+	reflect.TypeOf(&ast.ReturnStmt{}):     "ReturnStmt",
+}
+
+// nodeTypes is the reverse of nodeNames.
+var nodeTypes = func() map[string]reflect.Type {
+	r := make(map[string]reflect.Type, len(nodeNames))
+	for t, name := range nodeNames {
+		r[name] = t
+	}
+	return r
+}()
+
+// nodeField is a field of AST node in encoding: key and pointer to field in node.
+type nodeField struct {
+	key string
+	ptr interface{}
+}
+
+// nodeFields returns encoded fields of AST node n (n must be one of nodeNames).
+// It defines schema of encoding explicitly, so encoded data does not depend on changes of go/ast structures.
+// Any change of schema requires increment of EncodingVersion.
+func nodeFields(n ast.Node) []nodeField {
+	switch x := n.(type) {
+	case *ast.Ident:
+		return []nodeField{{"namePos", &x.NamePos}, {"name", &x.Name}}
+	case *ast.BasicLit:
+		return []nodeField{{"valuePos", &x.ValuePos}, {"kind", &x.Kind}, {"value", &x.Value}}
+	case *ast.CompositeLit:
+		return []nodeField{{"type", &x.Type}, {"lbrace", &x.Lbrace}, {"elts", &x.Elts}, {"rbrace", &x.Rbrace}, {"incomplete", &x.Incomplete}}
+	case *ast.FuncLit:
+		return []nodeField{{"type", &x.Type}, {"body", &x.Body}}
+	case *ast.ParenExpr:
+		return []nodeField{{"lparen", &x.Lparen}, {"x", &x.X}, {"rparen", &x.Rparen}}
+	case *ast.SelectorExpr:
+		return []nodeField{{"x", &x.X}, {"sel", &x.Sel}}
+	case *ast.IndexExpr:
+		return []nodeField{{"x", &x.X}, {"lbrack", &x.Lbrack}, {"index", &x.Index}, {"rbrack", &x.Rbrack}}
+	case *ast.IndexListExpr:
+		return []nodeField{{"x", &x.X}, {"lbrack", &x.Lbrack}, {"indices", &x.Indices}, {"rbrack", &x.Rbrack}}
+	case *ast.SliceExpr:
+		return []nodeField{{"x", &x.X}, {"lbrack", &x.Lbrack}, {"low", &x.Low}, {"high", &x.High}, {"max", &x.Max}, {"slice3", &x.Slice3}, {"rbrack", &x.Rbrack}}
+	case *ast.TypeAssertExpr:
+		return []nodeField{{"x", &x.X}, {"lparen", &x.Lparen}, {"type", &x.Type}, {"rparen", &x.Rparen}}
+	case *ast.CallExpr:
+		return []nodeField{{"fun", &x.Fun}, {"lparen", &x.Lparen}, {"args", &x.Args}, {"ellipsis", &x.Ellipsis}, {"rparen", &x.Rparen}}
+	case *ast.StarExpr:
+		return []nodeField{{"star", &x.Star}, {"x", &x.X}}
+	case *ast.UnaryExpr:
+		return []nodeField{{"opPos", &x.OpPos}, {"op", &x.Op}, {"x", &x.X}}
+	case *ast.BinaryExpr:
+		return []nodeField{{"x", &x.X}, {"opPos", &x.OpPos}, {"op", &x.Op}, {"y", &x.Y}}
+	case *ast.KeyValueExpr:
+		return []nodeField{{"key", &x.Key}, {"colon", &x.Colon}, {"value", &x.Value}}
+	case *ast.Ellipsis:
+		return []nodeField{{"ellipsis", &x.Ellipsis}, {"elt", &x.Elt}}
+	case *ast.ArrayType:
+		return []nodeField{{"lbrack", &x.Lbrack}, {"len", &x.Len}, {"elt", &x.Elt}}
+	case *ast.StructType:
+		return []nodeField{{"struct", &x.Struct}, {"fields", &x.Fields}, {"incomplete", &x.Incomplete}}
+	case *ast.FuncType:
+		return []nodeField{{"func", &x.Func}, {"typeParams", &x.TypeParams}, {"params", &x.Params}, {"results", &x.Results}}
+	case *ast.InterfaceType:
+		return []nodeField{{"interface", &x.Interface}, {"methods", &x.Methods}, {"incomplete", &x.Incomplete}}
+	case *ast.MapType:
+		return []nodeField{{"map", &x.Map}, {"key", &x.Key}, {"value", &x.Value}}
+	case *ast.ChanType:
+		return []nodeField{{"begin", &x.Begin}, {"arrow", &x.Arrow}, {"dir", &x.Dir}, {"value", &x.Value}}
+	case *ast.Field:
+		return []nodeField{{"names", &x.Names}, {"type", &x.Type}, {"tag", &x.Tag}}
+	case *ast.FieldList:
+		return []nodeField{{"opening", &x.Opening}, {"list", &x.List}, {"closing", &x.Closing}}
+	case *ast.BlockStmt:
+		return []nodeField{{"lbrace", &x.Lbrace}, {"list", &x.List}, {"rbrace", &x.Rbrace}}
+	case *ast.ReturnStmt:
+		return []nodeField{{"return", &x.Return}, {"results", &x.Results}}
+	default:
+		return nil
+	}
+}
+
+// tokens maps string representation of tokens to tokens.
+var tokens = func() map[string]token.Token {
+	r := make(map[string]token.Token)
+	for t := token.ILLEGAL; t <= token.TILDE; t++ {
+		r[t.String()] = t
+	}
+	return r
+}()
+
+var (
+	posType        = reflect.TypeOf(token.NoPos)
+	tokenType      = reflect.TypeOf(token.ILLEGAL)
+	nodeTypeKey    = "node"
+	errInvEncoding = errors.New("invalid encoded expression")
+)
+
+// encodeNode converts AST (or any part of it) x to value which may be marshaled to JSON.
+// Nodes are encoded according to nodeFields.
+// Positions are encoded as offsets (1-based) in file with given base and size, token.NoPos is omitted.
+func encodeNode(x reflect.Value, base, size int) (interface{}, error) {
+	switch t := x.Type(); {
+	case t == posType:
+		if p := x.Interface().(token.Pos); p.IsValid() && int(p) >= base && int(p) <= base+size {
+			return int(p) - base + 1, nil
+		}
+		return nil, nil
+	case t == tokenType:
+		return x.Interface().(token.Token).String(), nil
+	}
+
+	switch x.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if x.IsNil() {
+			return nil, nil
+		}
+		if x.Kind() == reflect.Interface {
+			return encodeNode(x.Elem(), base, size)
+		}
+		name, ok := nodeNames[x.Type()]
+		if !ok {
+			return nil, errors.New("unable to encode AST node " + x.Type().String())
+		}
+		r := map[string]interface{}{nodeTypeKey: name}
+		for _, f := range nodeFields(x.Interface().(ast.Node)) {
+			v, err := encodeNode(reflect.ValueOf(f.ptr).Elem(), base, size)
+			if err != nil {
+				return nil, err
+			}
+			if v != nil {
+				r[f.key] = v
+			}
+		}
+		return r, nil
+	case reflect.Slice:
+		if x.Len() == 0 {
+			return nil, nil
+		}
+		r := make([]interface{}, x.Len())
+		for i := range r {
+			var err error
+			if r[i], err = encodeNode(x.Index(i), base, size); err != nil {
+				return nil, err
+			}
+		}
+		return r, nil
+	case reflect.String, reflect.Bool, reflect.Int:
+		return x.Interface(), nil
+	default:
+		return nil, errors.New("unable to encode " + x.Type().String())
+	}
+}
+
+// decodeNode is the reverse of encodeNode, it stores decoded value in x.
+func decodeNode(data json.RawMessage, x reflect.Value, base, size int) error {
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	switch t := x.Type(); {
+	case t == posType:
+		var p int
+		if err := json.Unmarshal(data, &p); err != nil {
+			return err
+		}
+		if p < 0 || p > size+1 {
+			return errInvEncoding
+		}
+		if p != 0 {
+			x.Set(reflect.ValueOf(token.Pos(base + p - 1)))
+		}
+		return nil
+	case t == tokenType:
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		tok, ok := tokens[s]
+		if !ok {
+			return errors.New("unknown token " + strconv.Quote(s))
+		}
+		x.Set(reflect.ValueOf(tok))
+		return nil
+	}
+
+	switch x.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return err
+		}
+		var name string
+		if err := json.Unmarshal(fields[nodeTypeKey], &name); err != nil {
+			return err
+		}
+		t, ok := nodeTypes[name]
+		if !ok || !t.AssignableTo(x.Type()) {
+			return errors.New("unexpected AST node " + strconv.Quote(name))
+		}
+		n := reflect.New(t.Elem())
+		delete(fields, nodeTypeKey)
+		for _, f := range nodeFields(n.Interface().(ast.Node)) {
+			if err := decodeNode(fields[f.key], reflect.ValueOf(f.ptr).Elem(), base, size); err != nil {
+				return err
+			}
+			delete(fields, f.key)
+		}
+		for key := range fields {
+			return errors.New("unknown field " + strconv.Quote(key) + " of AST node " + strconv.Quote(name))
+		}
+		x.Set(n)
+		return nil
+	case reflect.Slice:
+		var elems []json.RawMessage
+		if err := json.Unmarshal(data, &elems); err != nil {
+			return err
+		}
+		r := reflect.MakeSlice(x.Type(), len(elems), len(elems))
+		for i := range elems {
+			if err := decodeNode(elems[i], r.Index(i), base, size); err != nil {
+				return err
+			}
+		}
+		x.Set(r)
+		return nil
+	case reflect.String, reflect.Bool, reflect.Int:
+		return json.Unmarshal(data, x.Addr().Interface())
+	default:
+		return errInvEncoding
+	}
+}
+
+// lookupArgDescription returns description of argument ident (in "x" or "pkg.X" notation) in normalized args.
+func lookupArgDescription(args Args, ident string) (r string, ok bool) {
+	for i := 0; i < len(ident); i++ {
+		if ident[i] == '.' {
+			pkg, ok := args[ident[:i]]
+			if !ok || pkg.Kind() != Package {
+				return "", false
+			}
+			v, ok := pkg.Package()[ident[i+1:]]
+			if !ok {
+				return "", false
+			}
+			return argDescription(v), true
+		}
+	}
+	v, ok := args[ident]
+	if !ok || v.Kind() == Package {
+		return "", false
+	}
+	return argDescription(v), true
+}
+
+// argDescription returns description of argument which is the same for arguments which are equivalent for compiled expression.
+// Types are described via typeIdentity, so different types with the same string representation have different descriptions.
+func argDescription(x Value) string {
+	switch x.Kind() {
+	case Datas:
+		d := x.Data()
+		switch d.Kind() {
+		case Regular:
+			return "variable of type " + typeIdentity(d.Regular().Type())
+		case TypedConst:
+			return "constant " + d.DeepValue() + " of type " + typeIdentity(d.TypedConst().Type())
+		case UntypedConst:
+			return "untyped " + d.UntypedConst().Kind().String() + " constant " + d.DeepValue()
+		default:
+			return d.DeepString()
+		}
+	case Type:
+		return "type " + typeIdentity(x.Type())
+	default:
+		return x.String()
+	}
+}
+
+// typeIdentity returns string representation of t which is the same only for identical types.
+// It is similar to t.String(), but named types (and unexported fields & methods) are qualified with full package path instead of package name.
+func typeIdentity(t reflect.Type) string {
+	if t.Name() != "" {
+		if t.PkgPath() == "" {
+			return t.Name()
+		}
+		return t.PkgPath() + "." + t.Name()
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + typeIdentity(t.Elem())
+	case reflect.Slice:
+		return "[]" + typeIdentity(t.Elem())
+	case reflect.Array:
+		return "[" + strconv.Itoa(t.Len()) + "]" + typeIdentity(t.Elem())
+	case reflect.Map:
+		return "map[" + typeIdentity(t.Key()) + "]" + typeIdentity(t.Elem())
+	case reflect.Chan:
+		switch t.ChanDir() {
+		case reflect.RecvDir:
+			return "<-chan " + typeIdentity(t.Elem())
+		case reflect.SendDir:
+			return "chan<- " + typeIdentity(t.Elem())
+		default:
+			return "chan (" + typeIdentity(t.Elem()) + ")"
+		}
+	case reflect.Func:
+		return "func" + signatureIdentity(t)
+	case reflect.Struct:
+		r := "struct {"
+		for i := 0; i < t.NumField(); i++ {
+			if i > 0 {
+				r += ";"
+			}
+			f := t.Field(i)
+			r += " "
+			if !f.Anonymous {
+				r += qualifiedName(f.PkgPath, f.Name) + " "
+			}
+			r += typeIdentity(f.Type)
+			if f.Tag != "" {
+				r += " " + strconv.Quote(string(f.Tag))
+			}
+		}
+		return r + " }"
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "interface {}"
+		}
+		r := "interface {"
+		for i := 0; i < t.NumMethod(); i++ {
+			if i > 0 {
+				r += ";"
+			}
+			m := t.Method(i)
+			r += " " + qualifiedName(m.PkgPath, m.Name) + signatureIdentity(m.Type)
+		}
+		return r + " }"
+	default:
+		return t.String()
+	}
+}
+
+// signatureIdentity returns representation of parameters and results of function type t (as used in typeIdentity).
+func signatureIdentity(t reflect.Type) string {
+	r := "("
+	for i := 0; i < t.NumIn(); i++ {
+		if i > 0 {
+			r += ", "
+		}
+		if t.IsVariadic() && i == t.NumIn()-1 {
+			r += "..." + typeIdentity(t.In(i).Elem())
+		} else {
+			r += typeIdentity(t.In(i))
+		}
+	}
+	r += ")"
+
+	switch t.NumOut() {
+	case 0:
+	case 1:
+		r += " " + typeIdentity(t.Out(0))
+	default:
+		r += " ("
+		for i := 0; i < t.NumOut(); i++ {
+			if i > 0 {
+				r += ", "
+			}
+			r += typeIdentity(t.Out(i))
+		}
+		r += ")"
+	}
+	return r
+}
+
+// qualifiedName returns name qualified with package path if name is not exported (pkgPath is not empty).
+func qualifiedName(pkgPath, name string) string {
+	if pkgPath == "" {
+		return name
+	}
+	return pkgPath + "." + name
+}
@@ -0,0 +1,157 @@
+package eval
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/constant"
+	htmltemplate "html/template"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestExpression_MarshalJSON(t *testing.T) {
+	srcs := []string{
+		"a + b*2",
+		`strings.ToUpper(s[1:]) + "!"`,
+		"[]int{1, 2, 3}[x] << 1",
+		"func(x int, y ...int) int { return x + len(y) }(1, 2, 3)",
+		"map[string]struct{ F int }{\"a\": {1}}[\"a\"].F",
+		"interface{}(x).(int) > 0 && (<-(chan int)(nil)) == 0",
+		"Max[int](1, 2)",
+	}
+	for _, src := range srcs {
+		e, err := ParseString(src, "pkg")
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := json.Marshal(e)
+		if err != nil {
+			t.Errorf("%v: %v", src, err)
+			continue
+		}
+		var d Expression
+		if err = json.Unmarshal(data, &d); err != nil {
+			t.Errorf("%v: %v", src, err)
+			continue
+		}
+		// Nodes are compared via encoding, because fields which are not part of schema (like BasicLit.ValueEnd in newer Go) are not restored
+		data2, err := json.Marshal(&d)
+		if err != nil || d.pkgPath != "pkg" || !bytes.Equal(data, data2) {
+			t.Errorf("%v: decoded expression differs from original", src)
+		}
+	}
+
+	// Positions of errors are kept
+	e, err := Parse("file.go", "1 +\n\tx[5]", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var d Expression
+	if err = json.Unmarshal(data, &d); err != nil {
+		t.Fatal(err)
+	}
+	_, err = d.EvalRaw(Args{"x": MakeDataRegularInterface([]int{1})})
+	if err == nil || err.Error() != "file.go:2:2: runtime error: index out of range [5] with length 1" {
+		t.Errorf("expect positioned error, got %v", err)
+	}
+
+	// Unsupported version
+	data = []byte(strings.Replace(string(data), `"version":3`, `"version":100`, 1))
+	if err = json.Unmarshal(data, &d); err == nil || err.Error() != "unsupported encoding version 100" {
+		t.Errorf("expect version error, got %v", err)
+	}
+
+	// Schema of nodes
+	e, err = ParseString("-a[1]", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := e.encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const expect = `{"node":"UnaryExpr","op":"-","opPos":1,"x":{"index":{"kind":"INT","node":"BasicLit","value":"1","valuePos":4},"lbrack":3,"node":"IndexExpr","rbrack":5,"x":{"name":"a","namePos":2,"node":"Ident"}}}`
+	if string(enc.Expr) != expect {
+		t.Errorf("expect %v, got %s", expect, enc.Expr)
+	}
+	data = []byte(`{"version":3,"expr":{"node":"Ident","name":"a","Obj":null}}`)
+	if err = json.Unmarshal(data, &d); err == nil || err.Error() != `unknown field "Obj" of AST node "Ident"` {
+		t.Errorf("expect unknown field error, got %v", err)
+	}
+}
+
+func TestLoadProgram(t *testing.T) {
+	spec := ArgTypes{
+		"x":          MakeDataRegularInterface(0),
+		"k":          MakeDataUntypedConst(constant.MakeInt64(2)),
+		"fmt.Sprint": MakeDataRegularInterface(fmt.Sprint),
+		"unused":     MakeDataRegularInterface(""),
+		"t":          MakeDataRegularInterface((*template.Template)(nil)),
+	}
+	e, err := ParseString("fmt.Sprint(x * k, t == nil)", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := e.Compile(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Unused arguments may differ
+	delete(spec, "unused")
+	loaded, err := LoadProgram(data, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := loaded.Eval(Args{"x": MakeDataRegularInterface(3)})
+	if err != nil || r.Data().Regular().Interface() != "6 true" {
+		t.Errorf("expect %q, got %v %v", "6 true", r, err)
+	}
+
+	tests := []struct {
+		ident string
+		arg   Value
+		err   ArgMismatchError
+	}{
+		{"x", MakeDataRegularInterface(int64(0)), ArgMismatchError{"x", "variable of type int", "variable of type int64"}},
+		{"k", MakeDataUntypedConst(constant.MakeInt64(3)), ArgMismatchError{"k", "untyped Int constant 2", "untyped Int constant 3"}},
+		{"fmt.Sprint", nil, ArgMismatchError{"fmt.Sprint", "variable of type func(...interface {}) string", ""}},
+		{"t", MakeDataRegularInterface((*htmltemplate.Template)(nil)), ArgMismatchError{"t", "variable of type *text/template.Template", "variable of type *html/template.Template"}},
+	}
+	for _, test := range tests {
+		spec1 := make(ArgTypes)
+		for ident, arg := range spec {
+			spec1[ident] = arg
+		}
+		if test.arg == nil {
+			delete(spec1, test.ident)
+		} else {
+			spec1[test.ident] = test.arg
+		}
+		_, err = LoadProgram(data, spec1)
+		var mismErr ArgMismatchError
+		if !errors.As(err, &mismErr) || mismErr != test.err {
+			t.Errorf("%v: expect %v, got %v", test.ident, test.err, err)
+		}
+	}
+
+	// Not compiled expression
+	data, err = json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = LoadProgram(data, spec); err == nil {
+		t.Error("expect error for not compiled expression")
+	}
+}
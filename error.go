@@ -1,17 +1,120 @@
 package eval
 
 import (
+	"errors"
 	"fmt"
 	"github.com/apaxa-go/helper/goh/asth"
+	"github.com/apaxa-go/helper/strconvh"
 	"go/ast"
 	"go/token"
+	"reflect"
+	"runtime"
+	"sort"
 )
 
+// ErrorCode is a machine-readable category of Error.
+// Codes are stable: values and names of existing codes are never changed, new codes are added to the end.
+// ErrorCode implements error interface, so errors.Is(err, code) reports whether err is an Error with given code.
+type ErrorCode int
+
+// Error codes.
+const (
+	UnknownError         ErrorCode = iota // error without category
+	InvalidAST                            // AST of expression is invalid (possible only for expressions made by hand)
+	SyntaxError                           // expression is syntactically invalid
+	UndefinedIdent                        // identifier, field or method is undefined (see Error.Ident)
+	NotExpression                         // type, package or built-in function used as value
+	NotType                               // value used as type
+	TypeMismatch                          // value of type Error.Actual used where Error.Expected is required
+	InvalidOperation                      // operation is not defined for operand(s)
+	InvalidConversion                     // conversion to type Error.Expected is impossible
+	WrongArgCount                         // too many or too few arguments in call
+	InvalidArgument                       // invalid argument in call (see Error.Ident and Error.Arg)
+	InvalidCompositeLit                   // invalid composite literal
+	IndexOutOfRange                       // index or slice bounds out of range
+	ConstOverflow                         // constant overflows type Error.Expected
+	NotFunction                           // call of non-function
+	WrongResultCount                      // function does not return exactly one value
+	CallError                             // called function returned error (see Error.Err)
+	CallPanic                             // called function panicked
+	TypeAssertionFailed                   // type assertion failed
+	NilDereference                        // nil pointer dereference or assignment to nil map
+	GenericInstantiation                  // generic function or type can not be instantiated
+	Unsupported                           // valid Go expression is not supported by package
+	InvalidScript                         // invalid statement in Script
+	Interrupted                           // evaluation interrupted by context
+	LimitExceeded                         // evaluation exceeds limit (see EvalOptions)
+	PolicyDenied                          // operation is denied by policy (see EvalOptions)
+	DivisionByZero                        // division by zero
+)
+
+var errorCodeNames = [...]string{
+	UnknownError:         "UnknownError",
+	InvalidAST:           "InvalidAST",
+	SyntaxError:          "SyntaxError",
+	UndefinedIdent:       "UndefinedIdent",
+	NotExpression:        "NotExpression",
+	NotType:              "NotType",
+	TypeMismatch:         "TypeMismatch",
+	InvalidOperation:     "InvalidOperation",
+	InvalidConversion:    "InvalidConversion",
+	WrongArgCount:        "WrongArgCount",
+	InvalidArgument:      "InvalidArgument",
+	InvalidCompositeLit:  "InvalidCompositeLit",
+	IndexOutOfRange:      "IndexOutOfRange",
+	ConstOverflow:        "ConstOverflow",
+	NotFunction:          "NotFunction",
+	WrongResultCount:     "WrongResultCount",
+	CallError:            "CallError",
+	CallPanic:            "CallPanic",
+	TypeAssertionFailed:  "TypeAssertionFailed",
+	NilDereference:       "NilDereference",
+	GenericInstantiation: "GenericInstantiation",
+	Unsupported:          "Unsupported",
+	InvalidScript:        "InvalidScript",
+	Interrupted:          "Interrupted",
+	LimitExceeded:        "LimitExceeded",
+	PolicyDenied:         "PolicyDenied",
+	DivisionByZero:       "DivisionByZero",
+}
+
+// String returns name of code (for example "UndefinedIdent").
+func (c ErrorCode) String() string {
+	if c >= 0 && int(c) < len(errorCodeNames) {
+		return errorCodeNames[c]
+	}
+	return "ErrorCode(" + strconvh.FormatInt(int(c)) + ")"
+}
+
+// Error implements standard error interface.
+func (c ErrorCode) Error() string { return c.String() }
+
+// MarshalText implements encoding.TextMarshaler (code is encoded as its name).
+func (c ErrorCode) MarshalText() ([]byte, error) { return []byte(c.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (c *ErrorCode) UnmarshalText(text []byte) error {
+	for i, name := range errorCodeNames {
+		if name == string(text) {
+			*c = ErrorCode(i)
+			return nil
+		}
+	}
+	return errors.New("unknown error code " + string(text))
+}
+
 // Error is used for all errors related to passed expression.
-// It describes problem (as text) and (in most cases) position of problem.
+// It describes problem (as text and as code with structured details) and (in most cases) position of problem.
+// Details which are not applicable to problem have zero values (Arg is -1).
 type Error struct {
-	Msg string        // description of problem
-	Pos asth.Position // position of problem
+	Msg      string        // description of problem
+	Pos      asth.Position // position of problem
+	Err      error         // underlying error (for example error returned by called function), may be nil
+	Code     ErrorCode     // category of problem
+	Ident    string        // identifier related to problem (undefined identifier, field, called built-in or generic function)
+	Expected reflect.Type  // required type
+	Actual   reflect.Type  // actual type of operand (nil for untyped constants)
+	Arg      int           // index of argument in call (as in Msg), -1 if problem is not related to argument
 }
 
 // Error implements standard error interface.
@@ -19,19 +122,120 @@ func (err Error) Error() string {
 	return err.Pos.String() + ": " + err.Msg
 }
 
+// Unwrap returns underlying error (if any).
+func (err Error) Unwrap() error {
+	return err.Err
+}
+
+// Is reports whether err has code target (if target is an ErrorCode).
+func (err Error) Is(target error) bool {
+	c, ok := target.(ErrorCode)
+	return ok && c == err.Code
+}
+
+// ErrorList is a list of errors (see Expression.CheckAll).
+type ErrorList []Error
+
+// Len implements sort.Interface.
+func (l ErrorList) Len() int { return len(l) }
+
+// Swap implements sort.Interface.
+func (l ErrorList) Swap(i, j int) { l[i], l[j] = l[j], l[i] }
+
+// Less implements sort.Interface.
+// Errors are ordered by file name, line, column and message.
+func (l ErrorList) Less(i, j int) bool {
+	a, b := l[i].Pos, l[j].Pos
+	switch {
+	case a.Filename != b.Filename:
+		return a.Filename < b.Filename
+	case a.Line != b.Line:
+		return a.Line < b.Line
+	case a.Column != b.Column:
+		return a.Column < b.Column
+	default:
+		return l[i].Msg < l[j].Msg
+	}
+}
+
+// Sort sorts list by position.
+func (l ErrorList) Sort() { sort.Sort(l) }
+
+// Error implements standard error interface.
+// It returns description of first error and count of others.
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", l[0], len(l)-1)
+}
+
+// Err returns nil if list is empty and list itself otherwise.
+func (l ErrorList) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
+
+// Unwrap returns all errors in list, so errors.Is and errors.As inspect each of them (since Go 1.20).
+func (l ErrorList) Unwrap() []error {
+	r := make([]error, len(l))
+	for i := range l {
+		r[i] = l[i]
+	}
+	return r
+}
+
+// RuntimeError describes run-time fault which causes panic in Go (integer division by zero, nil pointer dereference, index out of range, ...).
+// It implements runtime.Error and its text is the same as text of corresponding Go run-time panic.
+// Error caused by run-time fault wraps RuntimeError, so it may be extracted via errors.As.
+type RuntimeError struct {
+	msg string
+}
+
+var _ runtime.Error = RuntimeError{}
+
+// Error implements standard error interface.
+func (err RuntimeError) Error() string { return err.msg }
+
+// RuntimeError implements runtime.Error.
+func (RuntimeError) RuntimeError() {}
+
+// errorDetails is a machine-readable description of error (see Error for fields description).
+type errorDetails struct {
+	code             ErrorCode
+	ident            string
+	expected, actual reflect.Type
+	arg              int
+}
+
+var noDetails = errorDetails{arg: -1}
+
 type posError struct {
 	msg      string
 	pos, end token.Pos
+	err      error
+	errorDetails
 }
 
 func (err *posError) error(fset *token.FileSet) error {
 	if err == nil {
 		return nil
 	}
-	return Error{Msg: err.msg, Pos: asth.MakePosition(err.pos, err.end, fset)}
+	d := err.errorDetails
+	return Error{Msg: err.msg, Pos: asth.MakePosition(err.pos, err.end, fset), Err: err.err, Code: d.code, Ident: d.ident, Expected: d.expected, Actual: d.actual, Arg: d.arg}
 }
 
-type intError string
+type intError struct {
+	msg    string
+	err    error     // underlying error, may be nil
+	posErr *posError // already positioned error (for example error inside function literal), used as is instead of positioning
+	errorDetails
+}
 
 func toIntError(err error) *intError {
 	if err == nil {
@@ -40,7 +244,10 @@ func toIntError(err error) *intError {
 	return newIntError(err.Error())
 }
 func newIntError(msg string) *intError {
-	return (*intError)(&msg)
+	return &intError{msg: msg, errorDetails: noDetails}
+}
+func wrapIntError(msg string, err error) *intError {
+	return &intError{msg: msg, err: err, errorDetails: noDetails}
 }
 func newIntErrorf(format string, a ...interface{}) *intError {
 	return newIntError(fmt.Sprintf(format, a...))
@@ -50,12 +257,76 @@ func (err *intError) pos(n ast.Node) *posError {
 	if err == nil {
 		return nil
 	}
-	return &posError{msg: string(*err), pos: n.Pos(), end: n.End()}
+	if err.posErr != nil {
+		return err.posErr
+	}
+	return &posError{msg: err.msg, pos: n.Pos(), end: n.End(), err: err.err, errorDetails: err.errorDetails}
+}
+
+// at is like pos, but positions error at single point pos.
+func (err *intError) at(pos token.Pos) *posError {
+	if err == nil {
+		return nil
+	}
+	if err.posErr != nil {
+		return err.posErr
+	}
+	return &posError{msg: err.msg, pos: pos, err: err.err, errorDetails: err.errorDetails}
 }
 
 func (err *intError) noPos() *posError {
 	if err == nil {
 		return nil
 	}
-	return &posError{msg: string(*err)}
+	if err.posErr != nil {
+		return err.posErr
+	}
+	return &posError{msg: err.msg, err: err.err, errorDetails: err.errorDetails}
+}
+
+// Methods below set details of error. They may be called on nil error.
+
+func (err *intError) setCode(code ErrorCode) *intError {
+	if err != nil {
+		err.code = code
+	}
+	return err
+}
+func (err *intError) setIdent(ident string) *intError {
+	if err != nil {
+		err.ident = ident
+	}
+	return err
+}
+func (err *intError) setTypes(expected, actual reflect.Type) *intError {
+	if err != nil {
+		err.expected, err.actual = expected, actual
+	}
+	return err
+}
+func (err *intError) setArg(i int) *intError {
+	if err != nil {
+		err.arg = i
+	}
+	return err
+}
+
+// dataType returns type of x or nil if x is untyped.
+func dataType(x Data) reflect.Type {
+	switch x.Kind() {
+	case Regular:
+		return x.Regular().Type()
+	case TypedConst:
+		return x.TypedConst().Type()
+	default:
+		return nil
+	}
+}
+
+// valueType returns type of data x or nil if x is not a typed data.
+func valueType(x Value) reflect.Type {
+	if x.Kind() != Datas {
+		return nil
+	}
+	return dataType(x.Data())
 }
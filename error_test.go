@@ -1,7 +1,12 @@
 package eval
 
 import (
+	"errors"
+	"fmt"
+	"github.com/apaxa-go/helper/reflecth"
 	"go/token"
+	"reflect"
+	"runtime"
 	"testing"
 )
 
@@ -17,3 +22,195 @@ func TestIntError_NoPos(t *testing.T) {
 		t.Error("expect nil")
 	}
 }
+
+func TestError_Code(t *testing.T) {
+	intT := reflect.TypeOf(0)
+	strT := reflect.TypeOf("")
+	int8T := reflect.TypeOf(int8(0))
+	type testElement struct {
+		expr string
+		args Args
+		r    Error
+	}
+
+	tests := []testElement{
+		{"x + 1", nil, Error{Code: UndefinedIdent, Ident: "x", Arg: -1}},
+		{"s.Field", Args{"s": MakeDataRegularInterface(struct{ A int }{})}, Error{Code: UndefinedIdent, Ident: "Field", Actual: reflect.TypeOf(struct{ A int }{}), Arg: -1}},
+		{"a + 1.5", Args{"a": MakeDataRegularInterface(1)}, Error{Code: TypeMismatch, Expected: intT, Arg: -1}},
+		{"f(1, 2)", Args{"f": MakeDataRegularInterface(func(int, string) int { return 0 })}, Error{Code: TypeMismatch, Expected: strT, Arg: 1}},
+		{"f(a)", Args{"f": MakeDataRegularInterface(func(string) int { return 0 }), "a": MakeDataRegularInterface(1)}, Error{Code: TypeMismatch, Expected: strT, Actual: intT, Arg: 0}},
+		{"len(1)", nil, Error{Code: InvalidArgument, Ident: "len", Arg: -1}},
+		{"a[5]", Args{"a": MakeDataRegularInterface([]int{1})}, Error{Code: IndexOutOfRange, Arg: -1}},
+		{"f()", Args{"f": MakeDataRegularInterface(func() int { panic("boom") })}, Error{Code: CallPanic, Arg: -1}},
+		{"string(a)", Args{"a": MakeDataRegularInterface(1.5)}, Error{Code: InvalidConversion, Expected: strT, Actual: reflect.TypeOf(1.5), Arg: -1}},
+		{"int8(100) + int8(100)", nil, Error{Code: ConstOverflow, Expected: int8T, Arg: -1}},
+		{"-int8(-128)", nil, Error{Code: ConstOverflow, Expected: int8T, Arg: -1}},
+		{"int8(1) << 10", nil, Error{Code: ConstOverflow, Expected: int8T, Arg: -1}},
+		{"1 / 0", nil, Error{Code: DivisionByZero, Arg: -1}},
+		{"1.5 / 0", nil, Error{Code: DivisionByZero, Arg: -1}},
+		{"int8(1) % int8(0)", nil, Error{Code: DivisionByZero, Arg: -1}},
+		{"a + b", Args{"a": MakeDataRegularInterface(1), "b": MakeDataRegularInterface("")}, Error{Code: TypeMismatch, Expected: intT, Actual: strT, Arg: -1}},
+		{"a == b", Args{"a": MakeDataRegularInterface(1), "b": MakeDataRegularInterface("")}, Error{Code: TypeMismatch, Expected: intT, Actual: strT, Arg: -1}},
+		{"int8(1) + int16(1)", nil, Error{Code: TypeMismatch, Expected: int8T, Actual: reflect.TypeOf(int16(0)), Arg: -1}},
+	}
+
+	for _, test := range tests {
+		e, err := ParseString(test.expr, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = e.EvalToInterface(test.args)
+		var r Error
+		if !errors.As(err, &r) {
+			t.Errorf("%v: expect Error, got %v", test.expr, err)
+			continue
+		}
+		if r.Code != test.r.Code || r.Ident != test.r.Ident || r.Expected != test.r.Expected || r.Actual != test.r.Actual || r.Arg != test.r.Arg {
+			t.Errorf("%v: expect %v %q %v %v %v, got %v %q %v %v %v (%v)", test.expr, test.r.Code, test.r.Ident, test.r.Expected, test.r.Actual, test.r.Arg, r.Code, r.Ident, r.Expected, r.Actual, r.Arg, err)
+		}
+		if !errors.Is(err, test.r.Code) || errors.Is(err, UnknownError) {
+			t.Errorf("%v: errors.Is must match only code %v", test.expr, test.r.Code)
+		}
+	}
+
+	for c := UnknownError; c <= PolicyDenied; c++ {
+		text, _ := c.MarshalText()
+		var c2 ErrorCode
+		if err := c2.UnmarshalText(text); err != nil || c2 != c {
+			t.Errorf("%v: unable to decode code: %v %v", c, c2, err)
+		}
+	}
+	if s := ErrorCode(1000).String(); s != "ErrorCode(1000)" {
+		t.Errorf("unexpected name of unknown code %v", s)
+	}
+	var c ErrorCode
+	if err := c.UnmarshalText([]byte("NoSuchCode")); err == nil {
+		t.Error("expect error")
+	}
+}
+
+func TestError_OperationMessages(t *testing.T) {
+	args := Args{"a": MakeDataRegularInterface(1), "b": MakeDataRegularInterface(""), "c": MakeDataRegularInterface(int8(1)), "d": MakeDataRegularInterface(int16(1))}
+	type testElement struct {
+		expr string
+		code ErrorCode
+		msg  string
+	}
+
+	tests := []testElement{
+		{"int8(100)*2", ConstOverflow, "constant 200 overflows int8"},
+		{"-int8(-128)", ConstOverflow, "constant 128 overflows int8"},
+		{"int8(1) << 10", ConstOverflow, "constant 1024 overflows int8"},
+		{"1 / 0", DivisionByZero, "division by zero"},
+		{"1.5 / 0", DivisionByZero, "division by zero"},
+		{"int8(1) % int8(0)", DivisionByZero, "division by zero"},
+		{"a + b", TypeMismatch, "invalid operation: mismatched types int and string"},
+		{"a == b", TypeMismatch, "invalid operation: mismatched types int and string"},
+		{"c + d", TypeMismatch, "invalid operation: mismatched types int8 and int16"},
+		{"int8(1) == int16(1)", TypeMismatch, "invalid operation: mismatched types int8 and int16"},
+	}
+
+	for _, test := range tests {
+		e, err := ParseString(test.expr, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = e.EvalToInterface(args)
+		var r Error
+		if !errors.As(err, &r) || r.Code != test.code || r.Msg != test.msg {
+			t.Errorf("%v: expect %v %q, got %v", test.expr, test.code, test.msg, err)
+		}
+	}
+}
+
+type runtimeErrorSample struct{ F int }
+
+func (s runtimeErrorSample) Value() int { return s.F }
+
+func TestRuntimeError(t *testing.T) {
+	var p *runtimeErrorSample
+	i := reflect.New(reflecth.TypeEmptyInterface()).Elem()
+	i.Set(reflect.ValueOf(1))
+	args := Args{
+		"a":            MakeDataRegularInterface(1),
+		"z":            MakeDataRegularInterface(uint8(0)),
+		"p":            MakeDataRegularInterface(p),
+		"s":            MakeDataRegularInterface(make([]int, 2, 3)),
+		"str":          MakeDataRegularInterface("abc"),
+		"i":            MakeDataRegular(i),
+		"fmt.Stringer": MakeType(reflect.TypeOf((*fmt.Stringer)(nil)).Elem()),
+		"f":            MakeDataRegularInterface((func(int) (int, int))(nil)),
+		"g":            MakeDataRegularInterface((func(int) int)(nil)),
+	}
+
+	type testElement struct {
+		expr string
+		code ErrorCode
+		msg  string
+	}
+	tests := []testElement{
+		{"a / int(z)", DivisionByZero, "runtime error: integer divide by zero"},
+		{"a % int(z)", DivisionByZero, "runtime error: integer divide by zero"},
+		{"*p", NilDereference, "runtime error: invalid memory address or nil pointer dereference"},
+		{"p.F", NilDereference, "runtime error: invalid memory address or nil pointer dereference"},
+		{"p.Value()", NilDereference, "runtime error: invalid memory address or nil pointer dereference"},
+		{"g(a)", NilDereference, "runtime error: invalid memory address or nil pointer dereference"},
+		{"s[a+4]", IndexOutOfRange, "runtime error: index out of range [5] with length 2"},
+		{"s[-a]", IndexOutOfRange, "runtime error: index out of range [-1]"},
+		{"str[a+9]", IndexOutOfRange, "runtime error: index out of range [10] with length 3"},
+		{"s[1:a+3]", IndexOutOfRange, "runtime error: slice bounds out of range [:4] with capacity 3"},
+		{"str[1:a+3]", IndexOutOfRange, "runtime error: slice bounds out of range [:4] with length 3"},
+		{"s[a+1:1]", IndexOutOfRange, "runtime error: slice bounds out of range [2:1]"},
+		{"s[-a:]", IndexOutOfRange, "runtime error: slice bounds out of range [-1:]"},
+		{"s[0:1:a+3]", IndexOutOfRange, "runtime error: slice bounds out of range [::4] with capacity 3"},
+		{"s[0:a+2:2]", IndexOutOfRange, "runtime error: slice bounds out of range [:3:2]"},
+		{"s[a+1:1:2]", IndexOutOfRange, "runtime error: slice bounds out of range [2:1:]"},
+		{"i.(string)", TypeAssertionFailed, "interface conversion: interface {} is int, not string"},
+		{"i.(fmt.Stringer)", TypeAssertionFailed, "interface conversion: int is not fmt.Stringer: missing method String"},
+	}
+
+	for _, test := range tests {
+		e, err := ParseString(test.expr, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = e.EvalRaw(args)
+		var r Error
+		var rtErr runtime.Error
+		switch {
+		case !errors.As(err, &r):
+			t.Errorf("%v: expect Error, got %v", test.expr, err)
+		case r.Code != test.code || r.Msg != test.msg:
+			t.Errorf("%v: expect %v %q, got %v %q", test.expr, test.code, test.msg, r.Code, r.Msg)
+		case !errors.As(err, &rtErr) || rtErr.Error() != test.msg:
+			t.Errorf("%v: expect runtime.Error, got %v", test.expr, rtErr)
+		}
+	}
+
+	// Slices may be resliced up to capacity
+	e, err := ParseString("s[1:3]", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, err := e.EvalToInterface(args); err != nil || !reflect.DeepEqual(r, []int{0, 0}) {
+		t.Errorf("expect %v, got %v %v", []int{0, 0}, r, err)
+	}
+
+	// Call of nil function with multiple results
+	e, err = ParseString("f(a)", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = e.EvalMulti(args); !errors.Is(err, NilDereference) {
+		t.Errorf("expect nil dereference, got %v", err)
+	}
+
+	// Integer division by constant zero is a compile-time error
+	e, err = ParseString("a / 0", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = e.Compile(ArgTypes{"a": MakeDataRegularInterface(1)}); !errors.Is(err, DivisionByZero) || errors.As(err, new(runtime.Error)) {
+		t.Errorf("expect compile-time division by zero, got %v", err)
+	}
+}
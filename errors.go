@@ -1,6 +1,7 @@
 package eval
 
 import (
+	"fmt"
 	"github.com/apaxa-go/helper/strconvh"
 	"go/ast"
 	"go/constant"
@@ -9,10 +10,13 @@ import (
 )
 
 func identUndefinedError(ident string) *intError {
-	return newIntError("undefined: " + ident)
+	return newIntError("undefined: " + ident).setCode(UndefinedIdent).setIdent(ident)
+}
+func memberUndefinedError(t reflect.Type, name string) *intError {
+	return newIntError("undefined: ."+name).setCode(UndefinedIdent).setIdent(name).setTypes(nil, t)
 }
 func invAstError(msg string) *intError {
-	return newIntError("invalid AST: " + msg)
+	return newIntError("invalid AST: " + msg).setCode(InvalidAST)
 }
 func invAstNilError() *intError {
 	return invAstError("evaluate nil expr")
@@ -30,25 +34,25 @@ func invAstNilInterfaceMethodsError() *intError {
 	return invAstError("nil interface's methods")
 }
 func unsupportedInterfaceTypeError() *intError {
-	return newIntError("non empty interface type (with methods) declaration currently does not supported")
+	return newIntError("non empty interface type (with methods) declaration currently does not supported").setCode(Unsupported)
 }
 func invAstNonStringTagError() *intError {
 	return invAstError("tag is not of type string")
 }
 func invSelectorXError(x Value) *intError {
-	return newIntError("unable to select from " + x.DeepType())
+	return newIntError("unable to select from "+x.DeepType()).setCode(InvalidOperation).setTypes(nil, valueType(x))
 }
 func syntaxError(msg string) *intError {
-	return newIntError("syntax error: " + msg)
+	return newIntError("syntax error: " + msg).setCode(SyntaxError)
 }
 func syntaxInvBasLitError(literal string) *intError {
 	return syntaxError("invalid basic literal \"" + literal + "\"")
 }
 func indirectInvalError(x Value) *intError {
-	return newIntError("invalid indirect of " + x.String() + " (type " + x.DeepType() + ")")
+	return newIntError("invalid indirect of "+x.String()+" (type "+x.DeepType()+")").setCode(InvalidOperation).setTypes(nil, valueType(x))
 }
 func notExprError(x Value) *intError {
-	return newIntError(x.DeepType() + " is not an expression")
+	return newIntError(x.DeepType() + " is not an expression").setCode(NotExpression)
 }
 
 //func syntaxMisChanTypeError() *intError {
@@ -61,34 +65,122 @@ func notExprError(x Value) *intError {
 //	return syntaxError("final argument in variadic function missing type")
 //}
 func sliceInvTypeError(x Data) *intError {
-	return newIntError("cannot slice " + x.DeepString())
+	return newIntError("cannot slice "+x.DeepString()).setCode(InvalidOperation).setTypes(nil, dataType(x))
 }
 func notTypeError(x Value) *intError {
-	return newIntError(x.String() + " is not a type")
+	return newIntError(x.String() + " is not a type").setCode(NotType)
 }
 func initMixError() *intError {
-	return newIntError("mixture of field:value and value initializers")
+	return newIntError("mixture of field:value and value initializers").setCode(InvalidCompositeLit)
 }
 func initStructInvFieldNameError() *intError {
-	return newIntError("invalid field name in struct initializer")
+	return newIntError("invalid field name in struct initializer").setCode(InvalidCompositeLit)
 }
 func initArrayInvIndexError() *intError {
-	return newIntError("index must be non-negative integer constant")
+	return newIntError("index must be non-negative integer constant").setCode(InvalidCompositeLit)
 }
 func initArrayDupIndexError(i int) *intError {
-	return newIntError("duplicate index in array literal: " + strconvh.FormatInt(i))
+	return newIntError("duplicate index in array literal: " + strconvh.FormatInt(i)).setCode(InvalidCompositeLit)
 }
 func initMapMisKeyError() *intError {
-	return newIntError("missing key in map literal")
+	return newIntError("missing key in map literal").setCode(InvalidCompositeLit)
 }
 func initInvTypeError(t reflect.Type) *intError {
-	return newIntError("invalid type for composite literal: " + t.String())
+	return newIntError("invalid type for composite literal: "+t.String()).setCode(InvalidCompositeLit).setTypes(nil, t)
 }
 func funcInvEllipsisPos() *intError {
-	return newIntError("can only use ... with final input parameter")
+	return newIntError("can only use ... with final input parameter").setCode(SyntaxError)
+}
+func genericTypeArgsCountError(decl string, want, got int) *intError {
+	var s string
+	if got < want {
+		s = "not enough"
+	} else {
+		s = "too many"
+	}
+	return newIntError(s + " type arguments for " + decl + ": have " + strconvh.FormatInt(got) + ", want " + strconvh.FormatInt(want)).setCode(WrongArgCount).setIdent(decl)
+}
+func genericInstError(name string, typeArgs []reflect.Type, err error) *intError {
+	return wrapIntError("cannot instantiate "+name+genericTypeArgsString(typeArgs)+": "+err.Error(), err).setCode(GenericInstantiation).setIdent(name)
+}
+func genericNotInstantiatedError(x genericVal) *intError {
+	return newIntError("cannot use " + x.DeepType() + " " + x.name + " without instantiation").setCode(GenericInstantiation).setIdent(x.name)
+}
+func genericCannotInferError(name, typeParam string) *intError {
+	return newIntError("in call to " + name + ", cannot infer " + typeParam).setCode(GenericInstantiation).setIdent(name)
+}
+func genericInferConflictError(typeParam string, inferred, t reflect.Type) *intError {
+	return newIntError("type "+t.String()+" does not match inferred type "+inferred.String()+" for "+typeParam).setCode(GenericInstantiation).setTypes(inferred, t)
+}
+func genericInferMismError(name string, i int, err *intError) *intError {
+	return newIntError("in call to "+name+", argument #"+strconvh.FormatInt(i)+": "+err.msg).setCode(GenericInstantiation).setIdent(name).setTypes(err.expected, err.actual).setArg(i)
+}
+func indexMultipleError(x Value) *intError {
+	return newIntError("invalid operation: more than one index for " + x.DeepType()).setCode(InvalidOperation)
+}
+func funcLitInvBodyError() *intError {
+	return newIntError("function literal body must consist of single return statement with single result").setCode(Unsupported)
+}
+func funcLitResultCountError(n int) *intError {
+	return newIntError("function literal must have exactly one result, got " + strconvh.FormatInt(n)).setCode(Unsupported)
+}
+func scriptInvError() *intError {
+	return newIntError("script must be a list of statements").setCode(InvalidScript)
+}
+func scriptUnsupportedStmtError() *intError {
+	return newIntError("statement is not supported in script").setCode(Unsupported)
+}
+func scriptExprNotUsedError() *intError {
+	return newIntError("expression evaluated but not used").setCode(InvalidScript)
+}
+func scriptDefineNonNameError() *intError {
+	return newIntError("non-name on left side of :=").setCode(InvalidScript)
+}
+func scriptNoNewVarsError() *intError {
+	return newIntError("no new variables on left side of :=").setCode(InvalidScript)
+}
+func scriptOpAssignCountError(op token.Token) *intError {
+	return newIntError("assignment operation " + op.String() + " requires single-valued expressions").setCode(InvalidScript)
+}
+func scriptAssignCountError(vars, values int) *intError {
+	msg := "assignment mismatch: " + strconvh.FormatInt(vars) + " variables but " + strconvh.FormatInt(values) + " value"
+	if values != 1 {
+		msg += "s"
+	}
+	return newIntError(msg).setCode(InvalidScript)
+}
+func scriptBranchNotInLoopError(tok token.Token) *intError {
+	return newIntError(tok.String() + " is not in a loop").setCode(InvalidScript)
+}
+func scriptLabelError() *intError {
+	return newIntError("labels are not supported in script").setCode(Unsupported)
+}
+func scriptReturnCountError(n int) *intError {
+	return newIntError("return statement in script must have exactly one result, got " + strconvh.FormatInt(n)).setCode(InvalidScript)
+}
+func scriptMissingReturnError() *intError {
+	return newIntError("missing return").setCode(InvalidScript)
+}
+func scriptNonBoolCondError(x Data) *intError {
+	return newIntError("non-bool "+x.DeepString()+" used as condition").setCode(TypeMismatch).setTypes(reflect.TypeOf(false), dataType(x))
+}
+func scriptBlankValueError() *intError {
+	return newIntError("cannot use _ as value").setCode(InvalidScript)
+}
+func scriptUntypedNilError() *intError {
+	return newIntError("use of untyped nil in assignment").setCode(InvalidScript)
+}
+func scriptRangeError(x Data, twoVars bool) *intError {
+	if twoVars {
+		return newIntError("range over "+x.DeepString()+" permits only one iteration variable").setCode(InvalidScript).setTypes(nil, dataType(x))
+	}
+	return newIntError("cannot range over "+x.DeepString()).setCode(InvalidOperation).setTypes(nil, dataType(x))
+}
+func funcLitCallError(err *posError) *intError {
+	return &intError{msg: err.msg, err: err.err, posErr: err, errorDetails: err.errorDetails}
 }
 func cannotUseAsError(dst reflect.Type, src Data, in string) *intError {
-	return newIntError("cannot use " + src.DeepString() + " as type " + dst.String() + " in " + in)
+	return newIntError("cannot use "+src.DeepString()+" as type "+dst.String()+" in "+in).setCode(TypeMismatch).setTypes(dst, dataType(src))
 }
 func assignTypesMismError(dst reflect.Type, src Data) *intError {
 	return cannotUseAsError(dst, src, "assigment")
@@ -97,34 +189,34 @@ func appendMismTypeError(dst reflect.Type, src Data) *intError {
 	return cannotUseAsError(dst, src, "append")
 }
 func assignDstUnsettableError(dst Data) *intError {
-	return newIntError("cannot change " + dst.DeepString() + " in assignment")
+	return newIntError("cannot change "+dst.DeepString()+" in assignment").setCode(InvalidOperation).setTypes(nil, dataType(dst))
 }
 func compLitInvTypeError(t reflect.Type) *intError {
-	return newIntError("invalid type for composite literal: " + t.String())
+	return newIntError("invalid type for composite literal: "+t.String()).setCode(InvalidCompositeLit).setTypes(nil, t)
 }
 func compLitUnknFieldError(s reflect.Value, f string) *intError {
-	return newIntError("unknown " + s.Type().String() + " field '" + f + "' in struct literal")
+	return newIntError("unknown "+s.Type().String()+" field '"+f+"' in struct literal").setCode(UndefinedIdent).setIdent(f).setTypes(nil, s.Type())
 }
 func compLitArgsCountMismError(req, got int) *intError {
 	if req > got {
-		return newIntError("too few values in struct initializer")
+		return newIntError("too few values in struct initializer").setCode(InvalidCompositeLit)
 	}
-	return newIntError("too many values in struct initializer")
+	return newIntError("too many values in struct initializer").setCode(InvalidCompositeLit)
 }
 func compLitNegIndexError() *intError {
-	return newIntError("index must be non-negative integer constant")
+	return newIntError("index must be non-negative integer constant").setCode(InvalidCompositeLit)
 }
 func compLitIndexOutOfBoundsError(max, i int) *intError {
-	return newIntError("array index " + strconvh.FormatInt(i) + " out of bounds [0:" + strconvh.FormatInt(max) + "]")
+	return newIntError("array index " + strconvh.FormatInt(i) + " out of bounds [0:" + strconvh.FormatInt(max) + "]").setCode(IndexOutOfRange)
 }
 func invBinOpError(x, op, y, reason string) *intError {
-	return newIntError("invalid operation: " + x + " " + op + " " + y + " (" + reason + ")")
+	return newIntError("invalid operation: " + x + " " + op + " " + y + " (" + reason + ")").setCode(InvalidOperation)
 }
 func invBinOpUnknOpError(x Data, op token.Token, y Data) *intError {
 	return invBinOpError(x.DeepString(), op.String(), y.DeepString(), "operator "+op.String()+" not defined on nil")
 }
 func invBinOpTypesMismError(x Data, op token.Token, y Data) *intError {
-	return invBinOpError(x.DeepString(), op.String(), y.DeepString(), "mismatched types "+x.DeepType()+" and "+y.DeepType())
+	return invBinOpError(x.DeepString(), op.String(), y.DeepString(), "mismatched types "+x.DeepType()+" and "+y.DeepType()).setCode(TypeMismatch).setTypes(dataType(x), dataType(y))
 }
 func invBinOpTypesInvalError(x Data, op token.Token, y Data) *intError {
 	return invBinOpError(x.DeepString(), op.String(), y.DeepString(), "invalid types "+x.DeepType()+" and/or "+y.DeepType())
@@ -140,22 +232,35 @@ func invBinOpTypesInvalError(x Data, op token.Token, y Data) *intError {
 //	return invBinOpError(x.String(), op.String(), y.String(), "invalid operator")
 //}
 func invBinOpShiftCountError(x Data, op token.Token, y Data) *intError {
-	return invBinOpError(x.DeepString(), op.String(), y.DeepString(), "shift count type "+y.DeepType()+", must be unsigned integer")
+	return invBinOpError(x.DeepString(), op.String(), y.DeepString(), "shift count type "+y.DeepType()+", must be unsigned integer").setTypes(nil, dataType(y))
 }
 func invBinOpShiftArgError(x Data, op token.Token, y Data) *intError {
 	return invBinOpError(x.DeepString(), op.String(), y.DeepString(), "shift of type "+y.DeepType())
 }
 func callBuiltInArgsCountMismError(fn string, req, got int) *intError {
 	if req > got {
-		return newIntError("not enough arguments in call to " + fn)
+		return newIntError("not enough arguments in call to " + fn).setCode(WrongArgCount).setIdent(fn)
 	}
-	return newIntError("too many arguments in call to " + fn)
+	return newIntError("too many arguments in call to " + fn).setCode(WrongArgCount).setIdent(fn)
 }
 func invBuiltInArgError(fn string, x Data) *intError {
-	return newIntError("invalid argument " + x.DeepString() + " for " + fn)
+	return newIntError("invalid argument "+x.DeepString()+" for "+fn).setCode(InvalidArgument).setIdent(fn).setTypes(nil, dataType(x))
 }
 func invBuiltInArgAtError(fn string, pos int, x Data) *intError {
-	return newIntError("invalid argument #" + strconvh.FormatInt(pos) + " " + x.DeepString() + " for " + fn)
+	return newIntError("invalid argument #"+strconvh.FormatInt(pos)+" "+x.DeepString()+" for "+fn).setCode(InvalidArgument).setIdent(fn).setTypes(nil, dataType(x)).setArg(pos)
+}
+func invCustomBuiltInArgError(fn string, args []Value, i int) *intError {
+	switch {
+	case args[i].Kind() != Datas:
+		return notExprError(args[i])
+	case len(args) == 1:
+		return invBuiltInArgError(fn, args[i].Data())
+	default:
+		return invBuiltInArgAtError(fn, i, args[i].Data())
+	}
+}
+func customBuiltInNilResultError(fn string) *intError {
+	return newIntError("built-in function " + fn + " returns no value").setCode(WrongResultCount).setIdent(fn)
 }
 func invBuiltInArgsError(fn string, x []Data) *intError {
 	var msg string
@@ -165,28 +270,49 @@ func invBuiltInArgsError(fn string, x []Data) *intError {
 		}
 		msg += x[i].DeepString()
 	}
-	return newIntError("invalid arguments " + msg + " for " + fn)
+	return newIntError("invalid arguments " + msg + " for " + fn).setCode(InvalidArgument).setIdent(fn)
 }
 func callArgsCountMismError(req, got int) *intError {
 	if req > got {
-		return newIntError("not enough arguments in call")
+		return newIntError("not enough arguments in call").setCode(WrongArgCount)
 	}
-	return newIntError("too many arguments in call")
+	return newIntError("too many arguments in call").setCode(WrongArgCount)
 }
 func callNonFuncError(f Value) *intError {
-	return newIntError("cannot call non-function (type " + f.DeepType() + ")")
+	return newIntError("cannot call non-function (type "+f.DeepType()+")").setCode(NotFunction).setTypes(nil, valueType(f))
 }
 func callResultCountMismError(got int) *intError {
 	if got > 1 {
-		return newIntError("multiple-value in single-value context")
+		return newIntError("multiple-value in single-value context").setCode(WrongResultCount)
 	}
-	return newIntError("function call with no result used as value")
+	return newIntError("function call with no result used as value").setCode(WrongResultCount)
 }
 func callInvArgAtError(pos int, x Data, reqT reflect.Type) *intError {
-	return newIntError("cannot use " + x.DeepString() + " as type " + reqT.String() + " in argument #" + strconvh.FormatInt(pos))
+	return newIntError("cannot use "+x.DeepString()+" as type "+reqT.String()+" in argument #"+strconvh.FormatInt(pos)).setCode(TypeMismatch).setTypes(reqT, dataType(x)).setArg(pos)
+}
+func contextDoneError(err error) *intError {
+	return wrapIntError("evaluation interrupted: "+err.Error(), err).setCode(Interrupted)
+}
+func nodeLimitError(limit int) *intError {
+	err := NodeLimitError{limit}
+	return wrapIntError(err.Error(), err).setCode(LimitExceeded)
+}
+func allocLimitError(limit int64) *intError {
+	err := AllocLimitError{limit}
+	return wrapIntError(err.Error(), err).setCode(LimitExceeded)
 }
-func callPanicError(p interface{}) *intError {
-	return newIntErrorf("runtime panic in function call (%v)", p)
+func depthLimitError(limit int) *intError {
+	err := DepthLimitError{limit}
+	return wrapIntError(err.Error(), err).setCode(LimitExceeded)
+}
+func policyDeniedError(err error) *intError {
+	return wrapIntError(err.Error(), err).setCode(PolicyDenied)
+}
+func callReturnedError(err error) *intError {
+	return wrapIntError(err.Error(), err).setCode(CallError)
+}
+func callPanicError(p interface{}, stack []byte) *intError {
+	return wrapIntError(fmt.Sprintf("runtime panic in function call (%v)", p), PanicError{p, stack}).setCode(CallPanic)
 }
 func convertArgsCountMismError(t reflect.Type, req int, x []Data) *intError {
 	var msg string
@@ -202,29 +328,29 @@ func convertArgsCountMismError(t reflect.Type, req int, x []Data) *intError {
 	default:
 		msg = "no arguments to conversion to " + t.String()
 	}
-	return newIntError(msg)
+	return newIntError(msg).setCode(WrongArgCount).setTypes(t, nil)
 }
 func convertUnableError(t reflect.Type, x Data) *intError {
-	return newIntError("cannot convert " + x.DeepString() + " to type " + t.String())
+	return newIntError("cannot convert "+x.DeepString()+" to type "+t.String()).setCode(InvalidConversion).setTypes(t, dataType(x))
 }
 
 //func convertNilUnableError(t reflect.Type) *intError {
 //	return newIntError("cannot convertCall nil to type " + t.String())
 //}
 func undefIdentError(ident string) *intError {
-	return newIntError("undefined: " + ident)
+	return newIntError("undefined: " + ident).setCode(UndefinedIdent).setIdent(ident)
 }
 func invSliceOpError(x Data) *intError {
-	return newIntError("cannot slice " + x.DeepString())
+	return newIntError("cannot slice "+x.DeepString()).setCode(InvalidOperation).setTypes(nil, dataType(x))
 }
 func invSliceIndexError(low, high int) *intError {
-	return newIntError("invalid slice index: " + strconvh.FormatInt(low) + " > " + strconvh.FormatInt(high))
+	return newIntError("invalid slice index: " + strconvh.FormatInt(low) + " > " + strconvh.FormatInt(high)).setCode(IndexOutOfRange)
 }
 func invSlice3IndexOmitted() *intError {
-	return newIntError("only first index in 3-index slice can be omitted")
+	return newIntError("only first index in 3-index slice can be omitted").setCode(SyntaxError)
 }
 func invUnaryOp(x Data, op token.Token) *intError {
-	return newIntError("invalid operation: " + op.String() + " " + x.DeepType())
+	return newIntError("invalid operation: "+op.String()+" "+x.DeepType()).setCode(InvalidOperation).setTypes(nil, dataType(x))
 }
 
 //func invUnaryOpReason(x Value, op token.Token, reason interface{}) *intError {
@@ -234,66 +360,121 @@ func invUnaryOp(x Data, op token.Token) *intError {
 //	return invUnaryOpReason(x, op, "receive from non-chan type "+x.Type().String())
 //}
 func selectorUndefIdentError(t reflect.Type, name string) *intError {
-	return undefIdentError(t.String() + "." + name)
+	return undefIdentError(t.String()+"."+name).setIdent(name).setTypes(nil, t)
 }
 func arrayBoundInvBoundError(l Data) *intError {
-	return newIntError("invalid array bound " + l.DeepString())
+	return newIntError("invalid array bound "+l.DeepString()).setCode(InvalidArgument).setTypes(nil, dataType(l))
 }
 func arrayBoundNegError() *intError {
-	return newIntError("array bound must be non-negative")
+	return newIntError("array bound must be non-negative").setCode(InvalidArgument)
 }
 func convertWithEllipsisError(t reflect.Type) *intError {
-	return newIntError("invalid use of ... in type conversion to " + t.String())
+	return newIntError("invalid use of ... in type conversion to "+t.String()).setCode(InvalidConversion).setTypes(t, nil)
 }
 func callBuiltInWithEllipsisError(f string) *intError {
-	return newIntError("invalid use of ... with builtin " + f)
+	return newIntError("invalid use of ... with builtin " + f).setCode(InvalidArgument).setIdent(f)
 }
 func callRegularWithEllipsisError() *intError {
-	return newIntError("invalid use of ... in call")
+	return newIntError("invalid use of ... in call").setCode(InvalidArgument)
 }
 func makeInvalidTypeError(t reflect.Type) *intError {
-	return newIntError("cannot make type " + t.String())
+	return newIntError("cannot make type "+t.String()).setCode(InvalidArgument).setIdent("make").setTypes(nil, t).setArg(0)
 }
 func makeNotIntArgError(t reflect.Type, argPos int, arg Value) *intError {
-	return newIntError("non-integer argument #" + strconvh.FormatInt(argPos) + " in make(" + t.String() + ") - " + arg.DeepType())
+	return newIntError("non-integer argument #"+strconvh.FormatInt(argPos)+" in make("+t.String()+") - "+arg.DeepType()).setCode(InvalidArgument).setIdent("make").setTypes(nil, valueType(arg)).setArg(argPos)
 }
 func makeNegArgError(t reflect.Type, argPos int) *intError {
-	return newIntError("negative argument #" + strconvh.FormatInt(argPos) + " in make(" + t.String() + ")")
+	return newIntError("negative argument #" + strconvh.FormatInt(argPos) + " in make(" + t.String() + ")").setCode(InvalidArgument).setIdent("make").setArg(argPos)
 }
 func makeSliceMismArgsError(t reflect.Type) *intError {
-	return newIntError("len larger than cap in make(" + t.String() + ")")
+	return newIntError("len larger than cap in make(" + t.String() + ")").setCode(InvalidArgument).setIdent("make")
 }
 func appendFirstNotSliceError(x Data) *intError {
-	return newIntError("first argument to append must be slice; have " + x.DeepType())
+	return newIntError("first argument to append must be slice; have "+x.DeepType()).setCode(InvalidArgument).setIdent("append").setTypes(nil, dataType(x)).setArg(0)
 }
 func typeAssertLeftInvalError(x Data) *intError {
-	return newIntError("invalid type assertion: (non-interface type " + x.DeepType() + " on left)")
+	return newIntError("invalid type assertion: (non-interface type "+x.DeepType()+" on left)").setCode(InvalidOperation).setTypes(nil, dataType(x))
 }
 func typeAssertImposError(x reflect.Value, t reflect.Type) *intError {
-	return newIntError("impossible type " + t.String() + ": string does not implement " + x.Type().String())
+	return newIntError("impossible type "+t.String()+": string does not implement "+x.Type().String()).setCode(TypeMismatch).setTypes(t, x.Type())
 }
 func typeAssertFalseError(x reflect.Value, t reflect.Type) *intError {
-	return newIntError("interface conversion: " + x.Type().String() + " is not " + t.String())
+	var msg string
+	var actual reflect.Type
+	switch {
+	case x.IsNil():
+		msg = "interface conversion: " + x.Type().String() + " is nil, not " + t.String()
+	case t.Kind() == reflect.Interface:
+		actual = x.Elem().Type()
+		msg = "interface conversion: " + actual.String() + " is not " + t.String() + ": missing method " + missingMethod(actual, t)
+	default:
+		actual = x.Elem().Type()
+		msg = "interface conversion: " + x.Type().String() + " is " + actual.String() + ", not " + t.String()
+	}
+	err := RuntimeError{msg}
+	return wrapIntError(msg, err).setCode(TypeAssertionFailed).setTypes(t, actual)
+}
+
+// missingMethod returns name of first method of interface type t which is not implemented by type x.
+func missingMethod(x, t reflect.Type) string {
+	for i := 0; i < t.NumMethod(); i++ {
+		if m, ok := x.MethodByName(t.Method(i).Name); !ok || m.Type.NumIn() != t.Method(i).Type.NumIn()+1 {
+			return t.Method(i).Name
+		}
+	}
+	return ""
+}
+func commaOkInvalError() *intError {
+	return newIntError("comma-ok form allowed only for map index, type assertion and receive from channel").setCode(InvalidOperation)
 }
 func invOpError(op, reason string) *intError {
-	return newIntError("invalid operation: " + op + " (" + reason + ")")
+	return newIntError("invalid operation: " + op + " (" + reason + ")").setCode(InvalidOperation)
 }
 func invIndexOpError(x Data, i Data) *intError {
-	return invOpError(x.DeepString()+"["+i.DeepString()+"]", "type "+x.DeepType()+" does not support indexing")
+	return invOpError(x.DeepString()+"["+i.DeepString()+"]", "type "+x.DeepType()+" does not support indexing").setTypes(nil, dataType(x))
 }
 func indexOutOfRangeError(i int) *intError {
-	return newIntError("index " + strconvh.FormatInt(i) + " out of range")
+	return newIntError("index " + strconvh.FormatInt(i) + " out of range").setCode(IndexOutOfRange)
 }
 func cmpWithNilError(x Data, op token.Token) *intError {
-	return invBinOpError(x.DeepString(), op.String(), "nil", "compare with nil is not defined on "+x.DeepType())
+	return invBinOpError(x.DeepString(), op.String(), "nil", "compare with nil is not defined on "+x.DeepType()).setTypes(nil, dataType(x))
 }
 
 //func invMem(x Value) *intError {
 //	return newIntError("invalid memory address or nil pointer dereference (" + x.String() + " is nil)")
 //}
+func runtimeError(code ErrorCode, msg string) *intError {
+	err := RuntimeError{"runtime error: " + msg}
+	return wrapIntError(err.Error(), err).setCode(code)
+}
+func divideByZeroError() *intError {
+	return runtimeError(DivisionByZero, "integer divide by zero")
+}
+func nilDerefError() *intError {
+	return runtimeError(NilDereference, "invalid memory address or nil pointer dereference")
+}
+func nilMapError() *intError {
+	return runtimeError(NilDereference, "assignment to entry in nil map")
+}
+func indexBoundsError(i, length int) *intError {
+	if i < 0 {
+		return runtimeError(IndexOutOfRange, "index out of range ["+strconvh.FormatInt(i)+"]")
+	}
+	return runtimeError(IndexOutOfRange, "index out of range ["+strconvh.FormatInt(i)+"] with length "+strconvh.FormatInt(length))
+}
+func sliceBoundsError(bounds, what string, limit int) *intError {
+	msg := "slice bounds out of range " + bounds
+	if what != "" {
+		msg += " with " + what + " " + strconvh.FormatInt(limit)
+	}
+	return runtimeError(IndexOutOfRange, msg)
+}
+func divByConstZeroError() *intError {
+	return newIntError("invalid operation: division by zero").setCode(DivisionByZero)
+}
 func constOverflowType(x constant.Value, t reflect.Type) *intError {
-	return newIntError("constant " + x.ExactString() + " overflow " + t.String())
+	return newIntError("constant "+x.ExactString()+" overflow "+t.String()).setCode(ConstOverflow).setTypes(t, nil)
 }
 func interfaceMethodExpr() *intError {
-	return newIntError("Method expressions for interface types currently does not supported")
+	return newIntError("Method expressions for interface types currently does not supported").setCode(Unsupported)
 }
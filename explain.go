@@ -0,0 +1,216 @@
+package eval
+
+import (
+	"bytes"
+	"github.com/apaxa-go/helper/goh/asth"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"io"
+	"strings"
+)
+
+// Explanation describes evaluation of expression and its subexpressions (see EvalExplain).
+type Explanation struct {
+	Node      ast.Expr       // explained expression
+	Source    string         // source of expression in single line (in the same form as returned by Expression.String, but bodies of long function literals are elided)
+	Pos       asth.Position  // position of expression in source
+	Evaluated bool           // false if expression was skipped by short-circuit evaluation of && or ||
+	Value     Value          // result of expression, nil if expression was not evaluated or its evaluation failed
+	Err       error          // error of type Error if evaluation failed (it may be caused by subexpression)
+	Decisive  bool           // true if result of expression determined final result (see EvalExplain)
+	Children  []*Explanation // explanations of subexpressions in order of evaluation
+}
+
+// EvalExplain evaluates expression in the same way as EvalRaw does and returns explanation of result.
+// Explanation is a tree of evaluated subexpressions (parentheses are omitted) with theirs values.
+// Right operand of && or || which was not evaluated due to short-circuit evaluation is also included (with Evaluated false).
+//
+// Each subexpression is marked as decisive if final result depends on it.
+// Whole expression is always decisive.
+// Operand of && or || is decisive if its parent is decisive and operand has the same value as parent: for example, if "a && b" is false then only false operand is decisive, if it is true then both operands are decisive.
+// Evaluated operands of all other operations are decisive if theirs parent is decisive.
+// So for predicate "u.Age >= 18 && u.Country == "DE" && !u.Banned" which is false decisive subexpressions show the condition which failed.
+//
+// If evaluation fails then err is the same as returned by EvalRaw and r (if not nil) describes evaluation up to failure.
+// Tracer in options of expression is not used by EvalExplain.
+func (e *Expression) EvalExplain(args Args) (r *Explanation, err error) {
+	opts := e.options()
+	tracer := &explainTracer{}
+	opts.Tracer = tracer
+	_, err = e.WithOptions(opts).EvalRaw(args)
+	if tracer.root != nil {
+		tracer.root.complete(e.fset)
+		tracer.root.markDecisive(true)
+	}
+	return tracer.root, err
+}
+
+// String returns explanation as indented text (see WriteTo).
+func (x *Explanation) String() string {
+	var buf bytes.Buffer
+	_, _ = x.WriteTo(&buf)
+	return buf.String()
+}
+
+// WriteTo writes explanation to w as indented text: each subexpression in its own line indented by two spaces per level of nesting.
+// Line contains source of subexpression with its result (value, error or "not evaluated").
+// Decisive subexpressions are prefixed with "* ".
+func (x *Explanation) WriteTo(w io.Writer) (n int64, err error) {
+	cw := &countWriter{w: w}
+	err = x.write(cw, 0)
+	return cw.n, err
+}
+
+func (x *Explanation) write(w io.Writer, depth int) error {
+	line := strings.Repeat("  ", depth)
+	if x.Decisive {
+		line += "* "
+	}
+	line += x.Source
+	switch {
+	case !x.Evaluated:
+		line += " (not evaluated)"
+	case x.Err != nil:
+		line += " : " + x.Err.Error()
+	default:
+		line += " = " + explainValue(x.Value)
+	}
+	if _, err := io.WriteString(w, line+"\n"); err != nil {
+		return err
+	}
+	for _, c := range x.Children {
+		if err := c.write(w, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// explainValue returns human readable representation of v.
+func explainValue(v Value) string {
+	if v.Kind() == Datas {
+		return v.Data().DeepString()
+	}
+	return v.String()
+}
+
+// complete adds not evaluated operands of && and || to explanation.
+func (x *Explanation) complete(fset *token.FileSet) {
+	for _, c := range x.Children {
+		c.complete(fset)
+	}
+	bin, ok := x.Node.(*ast.BinaryExpr)
+	if !ok || (bin.Op != token.LAND && bin.Op != token.LOR) || x.Err != nil || len(x.Children) != 1 {
+		return
+	}
+	y := unparen(bin.Y)
+	x.Children = append(x.Children, &Explanation{Node: y, Source: exprSource(y), Pos: asth.MakePosition(y.Pos(), y.End(), fset)})
+}
+
+// markDecisive marks x and its subexpressions as decisive (see EvalExplain).
+// decisive reports whether parent of x is decisive.
+func (x *Explanation) markDecisive(decisive bool) {
+	x.Decisive = decisive
+	var value, logical bool
+	if bin, ok := x.Node.(*ast.BinaryExpr); ok && (bin.Op == token.LAND || bin.Op == token.LOR) && x.Value != nil && x.Value.Kind() == Datas {
+		value, logical = boolVal(x.Value.Data())
+	}
+	for _, c := range x.Children {
+		d := decisive && c.Evaluated
+		if d && logical {
+			d = c.Value != nil && c.Value.Kind() == Datas
+			if d {
+				v, ok := boolVal(c.Value.Data())
+				d = ok && v == value
+			}
+		}
+		c.markDecisive(d)
+	}
+}
+
+// exprSource returns source of e in the same form as Expression.String does, but in single line.
+// Bodies of function literals are elided if they do not fit into single line.
+func exprSource(e ast.Expr) string {
+	e = printableExpr(e, false)
+	src, err := singleLineSource(e)
+	if err == nil && strings.Contains(src, "\n") {
+		ast.Inspect(e, func(n ast.Node) bool {
+			if lit, ok := n.(*ast.FuncLit); ok {
+				lit.Body = &ast.BlockStmt{Lbrace: 1, List: []ast.Stmt{&ast.ExprStmt{X: ast.NewIdent("...")}}, Rbrace: 1}
+				return false
+			}
+			return true
+		})
+		src, err = singleLineSource(e)
+	}
+	if err != nil {
+		return "<invalid expression: " + err.Error() + ">"
+	}
+	return src
+}
+
+// singleLineSource prints printable expression e (see printableExpr).
+// All positions in printable expression are in the same line, so go/printer keeps short function literals in single line.
+func singleLineSource(e ast.Expr) (string, error) {
+	fset := token.NewFileSet()
+	fset.AddFile("", fset.Base(), 1)
+	var buf bytes.Buffer
+	err := printer.Fprint(&buf, fset, e)
+	return buf.String(), err
+}
+
+// explainTracer is a Tracer which builds Explanation.
+type explainTracer struct {
+	root   *Explanation
+	open   []*Explanation    // explanations of nodes being evaluated
+	bodies map[ast.Node]bool // nodes inside bodies of function literals
+}
+
+// explained reports whether node n is included in explanation.
+// Nodes inside body of function literal are evaluated on each call of function (which may happen inside called function or even after evaluation), so they are not included.
+func (t *explainTracer) explained(n ast.Node) bool {
+	_, paren := n.(*ast.ParenExpr)
+	_, isExpr := n.(ast.Expr)
+	return isExpr && !paren && !t.bodies[n]
+}
+
+// Enter implements Tracer interface.
+func (t *explainTracer) Enter(n ast.Node, pos asth.Position) {
+	if !t.explained(n) {
+		return
+	}
+	if lit, ok := n.(*ast.FuncLit); ok && lit.Body != nil {
+		if t.bodies == nil {
+			t.bodies = make(map[ast.Node]bool)
+		}
+		ast.Inspect(lit.Body, func(n ast.Node) bool {
+			if n != nil {
+				t.bodies[n] = true
+			}
+			return true
+		})
+	}
+	e := n.(ast.Expr)
+	x := &Explanation{Node: e, Source: exprSource(e), Pos: pos, Evaluated: true}
+	if len(t.open) == 0 {
+		if t.root != nil {
+			return // only the first top level expression is explained
+		}
+		t.root = x
+	} else {
+		parent := t.open[len(t.open)-1]
+		parent.Children = append(parent.Children, x)
+	}
+	t.open = append(t.open, x)
+}
+
+// Exit implements Tracer interface.
+func (t *explainTracer) Exit(n ast.Node, pos asth.Position, r Value, err error) {
+	if !t.explained(n) || len(t.open) == 0 {
+		return
+	}
+	x := t.open[len(t.open)-1]
+	t.open = t.open[:len(t.open)-1]
+	x.Value, x.Err = r, err
+}
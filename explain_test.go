@@ -0,0 +1,134 @@
+package eval
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type explainUser struct {
+	Age     int
+	Country string
+	Banned  bool
+}
+
+func TestExpression_EvalExplain(t *testing.T) {
+	args := ArgsFromInterfaces(ArgsI{"u": explainUser{Age: 20, Country: "FR"}})
+
+	type testElement struct {
+		expr string
+		r    string
+		err  bool
+	}
+	tests := []testElement{
+		{`u.Age >= 18 && u.Country == "DE" && !u.Banned`, `* u.Age >= 18 && u.Country == "DE" && !u.Banned = false (type bool)
+  * u.Age >= 18 && u.Country == "DE" = false (type untyped bool)
+    u.Age >= 18 = true (type untyped bool)
+      u.Age = 20 (type int)
+        u = {20 FR false} (type eval.explainUser)
+      18 = 18 (type untyped constant)
+    * u.Country == "DE" = false (type untyped bool)
+      * u.Country = FR (type string)
+        * u = {20 FR false} (type eval.explainUser)
+      * "DE" = "DE" (type untyped constant)
+  !u.Banned (not evaluated)
+`, false},
+		{`u.Age < 18 || (u.Country != "DE")`, `* u.Age < 18 || u.Country != "DE" = true (type untyped bool)
+  u.Age < 18 = false (type untyped bool)
+    u.Age = 20 (type int)
+      u = {20 FR false} (type eval.explainUser)
+    18 = 18 (type untyped constant)
+  * u.Country != "DE" = true (type untyped bool)
+    * u.Country = FR (type string)
+      * u = {20 FR false} (type eval.explainUser)
+    * "DE" = "DE" (type untyped constant)
+`, false},
+		{`u.Age > 18 && !u.Banned`, `* u.Age > 18 && !u.Banned = true (type bool)
+  * u.Age > 18 = true (type untyped bool)
+    * u.Age = 20 (type int)
+      * u = {20 FR false} (type eval.explainUser)
+    * 18 = 18 (type untyped constant)
+  * !u.Banned = true (type bool)
+    * u.Banned = false (type bool)
+      * u = {20 FR false} (type eval.explainUser)
+`, false},
+		{`u.Age > 1 && x`, `* u.Age > 1 && x : expression:1:14: undefined: x
+  * u.Age > 1 = true (type untyped bool)
+    * u.Age = 20 (type int)
+      * u = {20 FR false} (type eval.explainUser)
+    * 1 = 1 (type untyped constant)
+  * x : expression:1:14: undefined: x
+`, true},
+	}
+
+	for _, test := range tests {
+		e, err := ParseString(test.expr, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := e.EvalExplain(args)
+		if (err != nil) != test.err || r == nil {
+			t.Errorf("%v: unexpected error %v", test.expr, err)
+			continue
+		}
+		if s := r.String(); s != test.r {
+			t.Errorf("%v: expect\n%v\ngot\n%v", test.expr, test.r, s)
+		}
+	}
+
+	// Structured result
+	e, err := ParseString(`u.Age >= 18 && u.Banned`, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := e.EvalExplain(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Children) != 2 || r.Children[0].Decisive || !r.Children[1].Decisive || r.Children[1].Source != "u.Banned" || r.Children[1].Pos.Column != 16 {
+		t.Errorf("unexpected explanation %+v", r)
+	}
+	var buf bytes.Buffer
+	if n, err := r.WriteTo(&buf); err != nil || n != int64(buf.Len()) || buf.String() != r.String() {
+		t.Errorf("expect %v bytes, got %v %v", buf.Len(), n, err)
+	}
+
+	// Invalid arguments
+	if r, err = e.EvalExplain(Args{"u": nil}); err == nil || r != nil {
+		t.Errorf("expect error, got %v %v", r, err)
+	}
+}
+
+func TestExpression_EvalExplainFuncLit(t *testing.T) {
+	args := Args{"apply": MakeDataRegularInterface(myApply)}
+
+	// Function literal is written in single line, nodes of its body are not explained
+	e, err := ParseString("apply(func(x int) int {\n\treturn x * 2\n}, 3) > 1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := e.EvalExplain(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Source != "apply(func(x int) int { return x * 2 }, 3) > 1" {
+		t.Errorf("unexpected source %q", r.Source)
+	}
+	if s := r.String(); strings.Contains(s, "x * 2 =") || strings.Count(s, "\n") != 8 {
+		t.Errorf("unexpected explanation\n%v", s)
+	}
+
+	// Too long body is elided
+	body := "x" + strings.Repeat(" + x", 30)
+	e, err = ParseString("apply(func(x int) int { return "+body+" }, 3)", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err = e.EvalExplain(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Source != "apply(func(x int) int { ... }, 3)" {
+		t.Errorf("unexpected source %q", r.Source)
+	}
+}
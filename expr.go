@@ -1,8 +1,7 @@
 package eval
 
 import (
-	"errors"
-	"fmt"
+	"context"
 	"github.com/apaxa-go/helper/goh/constanth"
 	"go/ast"
 	"go/parser"
@@ -21,6 +20,14 @@ type Expression struct {
 	e       ast.Expr
 	fset    *token.FileSet
 	pkgPath string
+	opts    EvalOptions
+
+	spec   Args               // arguments known at compile time, used if identifier is not found in evaluation args
+	folded map[ast.Expr]Value // values of nodes computed at compile time
+	check  *checkState        // non nil only while compiling
+	ctx    context.Context    // non nil only if evaluation may be canceled (see EvalContext)
+	budget *evalBudget        // non nil only if evaluation is limited (see EvalOptions)
+	multi  *multiCall         // non nil only while evaluating top level call which may return any number of results (see astMulti)
 }
 
 // MakeExpression make expression with specified arguments.
@@ -29,7 +36,7 @@ type Expression struct {
 // fset is used to describe position of error and must be non nil (use token.NewFileSet instead).
 // pkgPath is fully qualified package name, for more details see package level documentation.
 func MakeExpression(e ast.Expr, fset *token.FileSet, pkgPath string) *Expression {
-	return &Expression{e, fset, pkgPath}
+	return &Expression{e: e, fset: fset, pkgPath: pkgPath}
 }
 
 // Parse parses filename or src for expression using parser.ParseExprFrom.
@@ -67,10 +74,43 @@ func ParseReader(src io.Reader, pkgPath string) (r *Expression, err error) {
 // EvalRaw evaluates expression with given arguments args.
 // Result of evaluation is Value.
 func (e *Expression) EvalRaw(args Args) (r Value, err error) {
+	err = e.eval(args, func(expr *Expression, args Args) (err *posError) {
+		r, err = expr.astExpr(e.e, args)
+		return
+	})
+	return
+}
+
+// EvalCommaOk evaluates expression with given arguments args in comma-ok form ("v, ok := expr").
+// Expression must be a map index expression ("m[k]"), type assertion ("x.(T)") or receive from channel ("<-c").
+// ok reports whether key is present in map, assertion holds or value was delivered by successful send to channel respectively.
+// If ok is false then r is zero value of corresponding type.
+func (e *Expression) EvalCommaOk(args Args) (r Value, ok bool, err error) {
+	err = e.eval(args, func(expr *Expression, args Args) (err *posError) {
+		r, ok, err = expr.astCommaOk(e.e, args)
+		return
+	})
+	return
+}
+
+// EvalMulti evaluates expression with given arguments args.
+// If expression is a call of function then all results of the call are returned (even if there is no result at all).
+// Results of such call are returned as is, they are not affected by EvalOptions.UnwrapErrors.
+// Any other expression produces exactly one result.
+func (e *Expression) EvalMulti(args Args) (r []Value, err error) {
+	err = e.eval(args, func(expr *Expression, args Args) (err *posError) {
+		r, err = expr.astMulti(e.e, args)
+		return
+	})
+	return
+}
+
+// eval validates and normalizes args and calls f with them and with expression prepared for single evaluation (see begin).
+func (e *Expression) eval(args Args, f func(expr *Expression, args Args) *posError) (err error) {
 	defer func() {
 		rec := recover()
 		if rec != nil {
-			err = errors.New(`BUG: unhandled panic "` + fmt.Sprint(rec) + `". Please report bug.`)
+			err = recoveredError(rec)
 		}
 	}()
 
@@ -86,10 +126,7 @@ func (e *Expression) EvalRaw(args Args) (r Value, err error) {
 		return
 	}
 
-	var posErr *posError
-	r, posErr = e.astExpr(e.e, args)
-	err = posErr.error(e.fset)
-	return
+	return f(e.begin(), args).error(e.fset)
 }
 
 // EvalToData evaluates expression with given arguments args.
@@ -2,8 +2,12 @@ package eval
 
 import (
 	"bytes"
+	"github.com/apaxa-go/helper/goh/constanth"
+	"github.com/apaxa-go/helper/reflecth"
 	"go/parser"
 	"go/token"
+	"reflect"
+	"strconv"
 	"testing"
 )
 
@@ -153,3 +157,119 @@ func TestMakeExpression(t *testing.T) {
 		t.Errorf("expect %v %v, got %v %v", 3, false, r, err)
 	}
 }
+
+func TestExpression_EvalCommaOk(t *testing.T) {
+	type testElement struct {
+		expr string
+		vars Args
+		r    interface{}
+		ok   bool
+		err  bool
+	}
+
+	c := make(chan int, 1)
+	c <- 3
+	cClosed := make(chan int)
+	close(cClosed)
+
+	tests := []testElement{
+		{`m["a"]`, ArgsFromInterfaces(ArgsI{"m": map[string]int{"a": 1}}), 1, true, false},
+		{`m["b"]`, ArgsFromInterfaces(ArgsI{"m": map[string]int{"a": 1}}), 0, false, false},
+		{`(m["a"])`, ArgsFromInterfaces(ArgsI{"m": map[string]int{"a": 1}}), 1, true, false},
+		{`m[1]`, ArgsFromInterfaces(ArgsI{"m": map[string]int{"a": 1}}), nil, false, true},
+		{`m[1]`, ArgsFromInterfaces(ArgsI{"m": []int{1, 2}}), nil, false, true},
+		{`x.(int)`, Args{"x": MakeDataRegular(reflecth.ValueOfPtr(&tmp6))}, 5, true, false},
+		{`x.(string)`, Args{"x": MakeDataRegular(reflecth.ValueOfPtr(&tmp6))}, "", false, false},
+		{`x.(int)`, ArgsFromInterfaces(ArgsI{"x": 5}), nil, false, true},
+		{`<-c`, ArgsFromInterfaces(ArgsI{"c": c}), 3, true, false},
+		{`<-c`, ArgsFromInterfaces(ArgsI{"c": cClosed}), 0, false, false},
+		{`<-c`, ArgsFromInterfaces(ArgsI{"c": (chan<- int)(c)}), nil, false, true},
+		{`<-c`, ArgsFromInterfaces(ArgsI{"c": 1}), nil, false, true},
+		{`a+1`, ArgsFromInterfaces(ArgsI{"a": 1}), nil, false, true},
+		{`-a`, ArgsFromInterfaces(ArgsI{"a": 1}), nil, false, true},
+	}
+
+	for _, test := range tests {
+		e, err := ParseString(test.expr, "")
+		if err != nil {
+			t.Fatalf("%v: %v", test.expr, err)
+		}
+		r, ok, err := e.EvalCommaOk(test.vars)
+		if err != nil != test.err {
+			t.Errorf("%v: expect error %v, got %v", test.expr, test.err, err)
+			continue
+		}
+		if test.err {
+			continue
+		}
+		if rI := r.Data().Regular().Interface(); rI != test.r || ok != test.ok {
+			t.Errorf("%v: expect %v %v, got %v %v", test.expr, test.r, test.ok, rI, ok)
+		}
+	}
+}
+
+func TestExpression_EvalMulti(t *testing.T) {
+	type testElement struct {
+		expr string
+		vars Args
+		r    []interface{}
+		err  bool
+	}
+
+	tests := []testElement{
+		{`f("12")`, ArgsFromInterfaces(ArgsI{"f": strconv.Atoi}), []interface{}{12, nil}, false},
+		{`f("1"+"2")`, ArgsFromInterfaces(ArgsI{"f": strconv.Atoi}), []interface{}{12, nil}, false},
+		{`f()`, ArgsFromInterfaces(ArgsI{"f": func() {}}), []interface{}{}, false},
+		{`f(1, a...)`, ArgsFromInterfaces(ArgsI{"f": myDiv, "a": []int{2, 4}}), []interface{}{[]int{2, 4}}, false},
+		{`f(1)`, ArgsFromInterfaces(ArgsI{"f": strconv.Atoi}), nil, true},
+		{`f(g())`, ArgsFromInterfaces(ArgsI{"f": strconv.Itoa, "g": strconv.Atoi}), nil, true},
+		{`f()`, ArgsFromInterfaces(ArgsI{"f": func() int { panic("test") }}), nil, true},
+		{`int8(1)`, nil, []interface{}{int8(1)}, false},
+		{`a+1`, ArgsFromInterfaces(ArgsI{"a": 1}), []interface{}{2}, false},
+		{`len("abc")`, nil, []interface{}{3}, false},
+	}
+
+	for _, test := range tests {
+		e, err := ParseString(test.expr, "")
+		if err != nil {
+			t.Fatalf("%v: %v", test.expr, err)
+		}
+		r, err := e.EvalMulti(test.vars)
+		if err != nil != test.err {
+			t.Errorf("%v: expect error %v, got %v", test.expr, test.err, err)
+			continue
+		}
+		if test.err {
+			continue
+		}
+		rI := make([]interface{}, len(r))
+		for i := range r {
+			switch d := r[i].Data(); d.Kind() {
+			case Regular:
+				rI[i] = d.Regular().Interface()
+			case TypedConst:
+				rI[i] = d.TypedConst().Value().Interface()
+			case UntypedConst:
+				rV, _ := constanth.DefaultValue(d.UntypedConst())
+				rI[i] = rV.Interface()
+			}
+		}
+		if !reflect.DeepEqual(rI, test.r) {
+			t.Errorf("%v: expect %v, got %v", test.expr, test.r, rI)
+		}
+	}
+
+	// Top level call is evaluated as any other node
+	e, err := ParseString(`f("12")`, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := ArgsFromInterfaces(ArgsI{"f": strconv.Atoi})
+	if _, err = e.WithOptions(EvalOptions{MaxNodes: 2}).EvalMulti(args); err == nil {
+		t.Error("expect node limit error")
+	}
+	tracer := &recordTracer{src: []string{`f("12")`}}
+	if _, err = e.WithOptions(EvalOptions{Tracer: tracer}).EvalMulti(args); err != nil || len(tracer.log) != 6 || tracer.log[5] != `f("12") = 12 (type int)` {
+		t.Errorf("unexpected trace %v %v", tracer.log, err)
+	}
+}
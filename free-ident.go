@@ -0,0 +1,201 @@
+package eval
+
+import (
+	"github.com/apaxa-go/helper/goh/asth"
+	"go/ast"
+	"sort"
+	"strings"
+)
+
+// FreeIdentifier describes identifier which is referenced by expression and should be passed via args.
+type FreeIdentifier struct {
+	Name      string          // identifier ("x") or qualified identifier ("pkg.Name")
+	Qualified bool            // Name is a selector "x.Name" which refers to member of package x or to field or method of variable x
+	Pos       []asth.Position // positions of all references in order of appearance
+}
+
+// Base returns identifier which is referenced if FreeIdentifier is not a package member: x for qualified identifier "x.Name" and Name itself otherwise.
+func (ident FreeIdentifier) Base() string {
+	if !ident.Qualified {
+		return ident.Name
+	}
+	return ident.Name[:strings.IndexByte(ident.Name, '.')]
+}
+
+// FreeIdentifiers returns identifiers referenced by expression in order of theirs first reference.
+// Built-in functions, built-in types, true, false, nil and parameters of function literals are not reported.
+// Selector "x.Name" is reported as qualified identifier "x.Name" (in the same notation as package members are passed via Args), because it is unknown without args is x a package or a variable.
+// Such identifiers have Qualified set, if x is not a package then x itself (see FreeIdentifier.Base) should be passed via args.
+// Keys of composite literals of struct types are treated as field names and are not reported.
+// If type of composite literal is unknown without args (named type, for example "T{x: 1}"), identifier keys are reported, because they may be keys of map.
+// FreeIdentifiers does not evaluate expression, so it may be called before args are known.
+func (e *Expression) FreeIdentifiers() []FreeIdentifier {
+	f := freeIdents{expr: e, index: make(map[string]int)}
+	f.walk(e.e, nil)
+	return f.r
+}
+
+type freeIdents struct {
+	expr  *Expression
+	r     []FreeIdentifier
+	index map[string]int // index in r by name
+}
+
+// freeIdentArg returns identifier of argument (in "x" or "pkg.X" notation) in normalized args to which free identifier refers.
+// Qualified identifier "x.Name" refers to argument x if x is not a package.
+func freeIdentArg(args Args, ident FreeIdentifier) (r string, ok bool) {
+	if _, ok = lookupArgDescription(args, ident.Name); ok || !ident.Qualified {
+		return ident.Name, ok
+	}
+	r = ident.Base()
+	_, ok = lookupArgDescription(args, r)
+	return
+}
+
+func isPredeclaredIdent(name string) bool {
+	return name == "true" || name == "false" || name == "nil" || isBuiltInFunc(name) || isBuiltInType(name)
+}
+
+func (f *freeIdents) add(name string, qualified bool, n ast.Node) {
+	pos := asth.MakePosition(n.Pos(), n.End(), f.expr.fset)
+	if i, ok := f.index[name]; ok {
+		f.r[i].Pos = append(f.r[i].Pos, pos)
+		return
+	}
+	f.index[name] = len(f.r)
+	f.r = append(f.r, FreeIdentifier{Name: name, Qualified: qualified, Pos: []asth.Position{pos}})
+}
+
+// walk collects free identifiers in n.
+// bound contains names of parameters of enclosing function literals.
+func (f *freeIdents) walk(n ast.Node, bound map[string]bool) {
+	ast.Inspect(n, func(n ast.Node) bool {
+		switch v := n.(type) {
+		case *ast.Ident:
+			if !bound[v.Name] && !isPredeclaredIdent(v.Name) {
+				f.add(v.Name, false, v)
+			}
+		case *ast.SelectorExpr:
+			if x, ok := v.X.(*ast.Ident); ok && !bound[x.Name] && !isPredeclaredIdent(x.Name) {
+				f.add(x.Name+"."+v.Sel.Name, true, v)
+			} else {
+				f.walk(v.X, bound)
+			}
+			return false
+		case *ast.Field: // names of fields, methods and parameters are not references
+			f.walk(v.Type, bound)
+			return false
+		case *ast.CompositeLit:
+			f.walkLit(v, v.Type, bound)
+			return false
+		case *ast.FuncLit:
+			f.walk(v.Type, bound)
+			inner := make(map[string]bool, len(bound))
+			for name := range bound {
+				inner[name] = true
+			}
+			for _, fields := range []*ast.FieldList{v.Type.Params, v.Type.Results} {
+				if fields == nil {
+					continue
+				}
+				for _, field := range fields.List {
+					for _, name := range field.Names {
+						inner[name.Name] = true
+					}
+				}
+			}
+			f.walk(v.Body, inner)
+			return false
+		}
+		return true
+	})
+}
+
+// walkLit collects free identifiers in composite literal lit of type t (t differs from lit.Type if type of lit is elided).
+// Identifier keys are not reported only if they are known to be field names.
+func (f *freeIdents) walkLit(lit *ast.CompositeLit, t ast.Expr, bound map[string]bool) {
+	if lit.Type != nil {
+		f.walk(lit.Type, bound)
+	}
+	keys := compositeLitKeys(t)
+	keyType, elemType := compositeLitElemTypes(t)
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if _, isIdent := kv.Key.(*ast.Ident); !isIdent || keys != litKeysFields {
+				f.walkElem(kv.Key, keyType, bound)
+			}
+			elt = kv.Value
+		}
+		f.walkElem(elt, elemType, bound)
+	}
+}
+
+// walkElem collects free identifiers in element (or key) e of composite literal, t is the implied type of e if its type is elided.
+func (f *freeIdents) walkElem(e ast.Expr, t ast.Expr, bound map[string]bool) {
+	if lit, ok := e.(*ast.CompositeLit); ok && lit.Type == nil {
+		f.walkLit(lit, t, bound)
+		return
+	}
+	f.walk(e, bound)
+}
+
+// usedIdents returns sorted list of identifiers of arguments (in "x" or "pkg.X" notation) which are used by expression e.
+// args must be normalized.
+func (e *Expression) usedIdents(args Args) []string {
+	var r []string
+	for _, ident := range e.FreeIdentifiers() {
+		if name, ok := freeIdentArg(args, ident); ok && !contains(r, name) {
+			r = append(r, name)
+		}
+	}
+	sort.Strings(r)
+	return r
+}
+
+// litKeys describes how identifier keys of composite literal are treated.
+type litKeys int
+
+const (
+	litKeysUnknown litKeys = iota // type of literal is unknown without args (named or elided type), so keys may be field names or expressions
+	litKeysFields                 // struct, keys are field names
+	litKeysExprs                  // array, slice or map, keys are expressions
+)
+
+// compositeLitKeys reports how keys of composite literal of type t are treated.
+func compositeLitKeys(t ast.Expr) litKeys {
+	switch t.(type) {
+	case *ast.StructType:
+		return litKeysFields
+	case *ast.ArrayType, *ast.MapType:
+		return litKeysExprs
+	default:
+		return litKeysUnknown
+	}
+}
+
+// isLitKeyExpr reports whether key of composite literal is surely an expression and not a field name, keys describes the literal.
+func isLitKeyExpr(key ast.Expr, keys litKeys) bool {
+	_, isIdent := key.(*ast.Ident)
+	return !isIdent || keys == litKeysExprs
+}
+
+// compositeLitElemTypes returns types of keys and elements of composite literal of type t.
+// They are implied types of keys and elements with elided type, nil means unknown type.
+func compositeLitElemTypes(t ast.Expr) (key, elem ast.Expr) {
+	switch v := t.(type) {
+	case *ast.ArrayType:
+		return nil, elidedType(v.Elt)
+	case *ast.MapType:
+		return elidedType(v.Key), elidedType(v.Value)
+	default:
+		return nil, nil
+	}
+}
+
+// elidedType returns type of composite literal with elided type if t is the type of element (&T{} may be elided to {} if t is *T).
+func elidedType(t ast.Expr) ast.Expr {
+	if star, ok := t.(*ast.StarExpr); ok {
+		return star.X
+	}
+	return t
+}
@@ -0,0 +1,70 @@
+package eval
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpression_FreeIdentifiers(t *testing.T) {
+	type testElement struct {
+		expr string
+		r    []string
+	}
+
+	tests := []testElement{
+		{"a + b*a", []string{"a", "b"}},
+		{"len(s) > 0 && s[0] == int(x) && true", []string{"s", "x"}},
+		{"strings.ToUpper(v.Name) + pkg.Const", []string{"strings.ToUpper", "v.Name", "pkg.Const"}},
+		{"f(x).Field", []string{"f", "x"}},
+		{"func(x int, y T) int { return x + z }(1, nil)", []string{"T", "z"}},
+		{"S{F: a, G: []int{k: 1}}", []string{"S", "F", "a", "G", "k"}}, // S may be a struct or a map
+		{"struct{ F int }{F: a}", []string{"a"}},
+		{"[]map[string]int{{k: 1}}", []string{"k"}},
+		{"[]*struct{ F int }{{F: a}}", []string{"a"}},
+		{"map[string]struct{ F int }{k: {F: a}}", []string{"k", "a"}},
+		{"map[K]int{k: 1}[k]", []string{"K", "k"}},
+		{"struct{ F int; G pkg.T }{}", []string{"pkg.T"}},
+		{"nil == interface{ M(x X) }(nil)", []string{"X"}},
+		{"Max[int](a, 1)", []string{"Max", "a"}},
+	}
+
+	for _, test := range tests {
+		e, err := ParseString(test.expr, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var r []string
+		for _, ident := range e.FreeIdentifiers() {
+			r = append(r, ident.Name)
+		}
+		if !reflect.DeepEqual(r, test.r) {
+			t.Errorf("%v: expect %v, got %v", test.expr, test.r, r)
+		}
+	}
+
+	e, err := ParseString("a + b*a", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := e.FreeIdentifiers()
+	if len(r[0].Pos) != 2 || r[0].Pos[0].Column != 1 || r[0].Pos[1].Column != 7 || r[1].Pos[0].Column != 5 {
+		t.Errorf("unexpected positions %v", r)
+	}
+
+	// Selector on variable
+	e, err = ParseString("v.Name + pkg.Const + x", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r = e.FreeIdentifiers()
+	if !r[0].Qualified || r[0].Base() != "v" || !r[1].Qualified || r[1].Base() != "pkg" || r[2].Qualified || r[2].Base() != "x" {
+		t.Errorf("unexpected qualification %v", r)
+	}
+	args := Args{"v": MakeDataRegularInterface(SampleStruct{}), "pkg.Const": MakeDataRegularInterface(1), "x": MakeDataRegularInterface(2)}
+	if err = args.normalize(); err != nil {
+		t.Fatal(err)
+	}
+	if used := e.usedIdents(args); !reflect.DeepEqual(used, []string{"pkg.Const", "v", "x"}) {
+		t.Errorf("unexpected used identifiers %v", used)
+	}
+}
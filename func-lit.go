@@ -0,0 +1,163 @@
+package eval
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+)
+
+// funcLitPanic is a panic value used to pass error occurred while evaluating body of function literal through the call of function.
+type funcLitPanic struct {
+	err  *posError
+	fset *token.FileSet
+}
+
+// Error implements standard error interface (useful if function created by expression is called outside of evaluation).
+func (p funcLitPanic) Error() string {
+	return p.err.error(p.fset).Error()
+}
+
+// astFuncLit makes function from function literal.
+// Body of function literal must consist of single return statement with single expression ("func(x int) int { return x*2 }").
+// Function literal captures args, its parameters are added to them on each call.
+func (expr *Expression) astFuncLit(e *ast.FuncLit, args Args) (r Value, err *posError) {
+	names, in, variadic, err := expr.funcLitFields(e.Type.Params, true, args)
+	if err != nil {
+		return
+	}
+	resNames, out, _, err := expr.funcLitFields(e.Type.Results, false, args)
+	if err != nil {
+		return
+	}
+	if len(out) != 1 {
+		return nil, funcLitResultCountError(len(out)).pos(e.Type)
+	}
+	outT := out[0]
+
+	if e.Body == nil || len(e.Body.List) != 1 {
+		return nil, funcLitInvBodyError().pos(e)
+	}
+	ret, ok := e.Body.List[0].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return nil, funcLitInvBodyError().pos(e.Body.List[0])
+	}
+	body := ret.Results[0]
+
+	names = append(names[:len(names):len(names)], resNames...) // named results are also available in body
+
+	// Check body before making function (like Go checks it at compile time)
+	scope := funcLitScope(args, names, append(probes(in), probes(out)...))
+	var res Data
+	if expr.checking() {
+		res, err = expr.astExprAsData(body, scope)
+	} else {
+		res, err = expr.staticExpr(body, scope)
+	}
+	if err != nil {
+		return
+	}
+	if !res.AssignableTo(outT) {
+		return nil, cannotUseAsError(outT, res, "return argument").pos(body)
+	}
+
+	fT := reflect.FuncOf(in, out, variadic)
+	if expr.checking() {
+		return MakeDataRegular(probe(fT)), nil
+	}
+
+	// Function may be called after evaluation or concurrently, so it captures only immutable parts of expression.
+	// Each call has its own budget and is not affected by context of evaluation which makes function.
+	lit := *expr
+	lit.check, lit.ctx, lit.budget, lit.multi = nil, nil, nil, nil
+	f := func(inV []reflect.Value) []reflect.Value {
+		expr := lit.begin()
+		defer func() {
+			if rec := recover(); rec != nil {
+				unwrapRepanic(rec) // function may be called outside of evaluation, so internal panic value must not leak
+				panic(rec)
+			}
+		}()
+		scope := funcLitScope(args, names, append(copyValues(inV), zeroValues(out)...))
+		res, err := expr.astExprAsData(body, scope)
+		if err != nil {
+			panic(funcLitPanic{err, expr.fset})
+		}
+		rV, ok := res.Assign(outT)
+		if !ok {
+			panic(funcLitPanic{cannotUseAsError(outT, res, "return argument").pos(body), expr.fset})
+		}
+		return []reflect.Value{rV}
+	}
+	return MakeDataRegular(reflect.MakeFunc(fT, f)), nil
+}
+
+// funcLitFields is like funcTranslateArgs, but also returns names of parameters (one name for each type, "" for unnamed parameters).
+func (expr *Expression) funcLitFields(fields *ast.FieldList, ellipsisAllowed bool, args Args) (names []string, types []reflect.Type, variadic bool, err *posError) {
+	if fields == nil {
+		return
+	}
+	for i := range fields.List {
+		// check for variadic
+		if _, ellipsis := fields.List[i].Type.(*ast.Ellipsis); ellipsis {
+			if !ellipsisAllowed || i != len(fields.List)-1 || len(fields.List[i].Names) > 1 {
+				return nil, nil, false, funcInvEllipsisPos().pos(fields.List[i])
+			}
+			variadic = true
+		}
+		// calc type
+		var t reflect.Type
+		t, err = expr.astExprAsType(fields.List[i].Type, args)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		if len(fields.List[i].Names) == 0 {
+			names = append(names, "")
+			types = append(types, t)
+		}
+		for _, name := range fields.List[i].Names {
+			names = append(names, name.Name)
+			types = append(types, t)
+		}
+	}
+	return
+}
+
+// funcLitScope returns args which are available inside body of function literal: captured args and named parameters.
+func funcLitScope(args Args, names []string, values []reflect.Value) Args {
+	r := make(Args, len(args)+len(names))
+	for ident, arg := range args {
+		r[ident] = arg
+	}
+	for i, name := range names {
+		if name != "" && name != "_" {
+			r[name] = MakeDataRegular(values[i])
+		}
+	}
+	return r
+}
+
+func probes(types []reflect.Type) []reflect.Value {
+	r := make([]reflect.Value, len(types))
+	for i := range types {
+		r[i] = probe(types[i])
+	}
+	return r
+}
+
+func zeroValues(types []reflect.Type) []reflect.Value {
+	r := make([]reflect.Value, len(types))
+	for i := range types {
+		r[i] = reflect.New(types[i]).Elem()
+	}
+	return r
+}
+
+// copyValues returns addressable copies of x.
+func copyValues(x []reflect.Value) []reflect.Value {
+	r := make([]reflect.Value, len(x))
+	for i := range x {
+		r[i] = reflect.New(x[i].Type()).Elem()
+		r[i].Set(x[i])
+	}
+	return r
+}
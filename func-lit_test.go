@@ -0,0 +1,96 @@
+package eval
+
+import (
+	"context"
+	"go/ast"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestExpression_astFuncLit(t *testing.T) {
+	// Error inside body of function literal must be positioned inside literal
+	e, err := ParseString("apply(func(x int) int { return s[x] }, 2)", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = e.EvalRaw(Args{"apply": MakeDataRegularInterface(myApply), "s": MakeDataRegularInterface([]int{5, 6})})
+	if evalErr, ok := err.(Error); !ok || evalErr.Pos.Column != 32 {
+		t.Errorf("expect error at column 32, got %v", err)
+	}
+
+	// Function is usable outside of evaluation
+	e, err = ParseString("func(x int) int { return s[x] }", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := e.EvalToInterface(Args{"s": MakeDataRegularInterface([]int{5, 6})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, ok := r.(func(int) int)
+	if !ok {
+		t.Fatalf("expect func(int) int, got %T", r)
+	}
+	if v := f(1); v != 6 {
+		t.Errorf("expect %v, got %v", 6, v)
+	}
+	func() {
+		defer func() {
+			rec := recover()
+			if err, ok := rec.(error); !ok || err.Error() != "expression:1:26: runtime error: index out of range [2] with length 2" {
+				t.Errorf("expect positioned error, got %v", rec)
+			}
+		}()
+		f(2)
+	}()
+}
+
+func TestExpression_astFuncLitInvEllipsis(t *testing.T) {
+	// Parser rejects such literal itself, so AST is built manually
+	lit := &ast.FuncLit{
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{List: []*ast.Field{
+				{Names: []*ast.Ident{ast.NewIdent("x")}, Type: &ast.Ellipsis{Elt: ast.NewIdent("int")}},
+				{Names: []*ast.Ident{ast.NewIdent("y")}, Type: ast.NewIdent("int")},
+			}},
+			Results: &ast.FieldList{List: []*ast.Field{{Type: ast.NewIdent("int")}}},
+		},
+		Body: &ast.BlockStmt{List: []ast.Stmt{&ast.ReturnStmt{Results: []ast.Expr{&ast.BasicLit{Kind: token.INT, Value: "1"}}}}},
+	}
+	e := MakeExpression(&ast.CallExpr{Fun: ast.NewIdent("f"), Args: []ast.Expr{lit}}, token.NewFileSet(), "")
+	_, err := e.EvalRaw(Args{"f": MakeDataRegularInterface(func(g func(int, int) int) int { return g(3, 2) })})
+	if err == nil || !strings.HasSuffix(err.Error(), "can only use ... with final input parameter") {
+		t.Errorf("expect ellipsis error, got %v", err)
+	}
+}
+
+func TestExpression_astFuncLitEscaped(t *testing.T) {
+	// Each call of function has its own budget
+	e, err := ParseString("func(x int) int { return x + 1 }", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := e.WithOptions(EvalOptions{MaxNodes: 10}).EvalToInterface(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := r.(func(int) int)
+	for i := 0; i < 10; i++ {
+		if v := f(i); v != i+1 {
+			t.Fatalf("expect %v, got %v", i+1, v)
+		}
+	}
+
+	// Function is not affected by context of evaluation which made it
+	ctx, cancel := context.WithCancel(context.Background())
+	rV, err := e.EvalContext(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	f = rV.Data().Regular().Interface().(func(int) int)
+	if v := f(1); v != 2 {
+		t.Errorf("expect %v, got %v", 2, v)
+	}
+}
@@ -0,0 +1,384 @@
+package eval
+
+import (
+	"errors"
+	"github.com/apaxa-go/helper/goh/constanth"
+	"github.com/apaxa-go/helper/reflecth"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+)
+
+// genericVal stores generic function or generic type.
+// reflect is unable to instantiate generics, so all instantiations are performed by user defined function.
+type genericVal struct {
+	decl       string         // declaration of generic (used in error messages)
+	name       string         // name of generic
+	typeParams []string       // names of type parameters
+	params     *ast.FieldList // parameters of generic function, nil if unknown (only for generic types)
+	err        error          // error in declaration, reported on arguments validation
+
+	typeArgs []reflect.Type // already bound type arguments (for partially instantiated generic function)
+
+	isType   bool                                                 // true for generic type, false for generic function
+	instFunc func(typeArgs []reflect.Type) (reflect.Value, error) // used only for generic function
+	instType func(typeArgs []reflect.Type) (reflect.Type, error)  // used only for generic type
+}
+
+func (x genericVal) Kind() ValueKind {
+	if x.isType {
+		return GenericType
+	}
+	return GenericFunc
+}
+func (x genericVal) DeepType() string {
+	if x.isType {
+		return "generic type"
+	}
+	return "generic function"
+}
+func (x genericVal) String() string          { return x.DeepType() + " value " + x.decl }
+func (genericVal) Data() Data                { panic("") }
+func (genericVal) Type() reflect.Type        { panic("") }
+func (genericVal) BuiltInFunc() string       { panic("") }
+func (genericVal) Package() map[string]Value { panic("") }
+func (genericVal) implementsValue()          {}
+
+// MakeGenericFunc makes Value which stores generic function.
+// decl is a declaration of function without "func" keyword and body, for example "Map[T, U any](s []T, f func(T) U) []U".
+// Parameters of function are used to infer type arguments from call arguments, so it is possible to call generic function without explicit instantiation ("Map(s, f)").
+// inst must return function instantiated with given type arguments (for example, "reflect.ValueOf(Map[int, string])").
+// Errors returned by inst are reported as evaluation errors.
+// Errors in decl are reported while validating arguments.
+func MakeGenericFunc(decl string, inst func(typeArgs []reflect.Type) (reflect.Value, error)) Value {
+	r := genericVal{decl: decl, instFunc: inst}
+	f, err := parseGenericDecl("func " + decl)
+	if err == nil {
+		fD, ok := f.Decls[0].(*ast.FuncDecl)
+		switch {
+		case !ok || fD.Recv != nil || fD.Body != nil:
+			err = errors.New("not a function declaration")
+		case fD.Type.TypeParams == nil:
+			err = errors.New("function has no type parameters")
+		default:
+			r.name = fD.Name.Name
+			r.typeParams = fieldsNames(fD.Type.TypeParams)
+			r.params = fD.Type.Params
+		}
+	}
+	if err != nil {
+		r.err = errors.New("invalid generic function declaration " + decl + ": " + err.Error())
+	}
+	return r
+}
+
+// MakeGenericType makes Value which stores generic type.
+// decl is a declaration of type name with type parameters, for example "Pair[K comparable, V any]".
+// inst must return type instantiated with given type arguments (for example, "reflect.TypeOf(Pair[string, int]{})").
+// Errors returned by inst are reported as evaluation errors.
+// Errors in decl are reported while validating arguments.
+func MakeGenericType(decl string, inst func(typeArgs []reflect.Type) (reflect.Type, error)) Value {
+	r := genericVal{decl: decl, isType: true, instType: inst}
+	f, err := parseGenericDecl("type " + decl + " struct{}")
+	if err == nil {
+		gD, ok := f.Decls[0].(*ast.GenDecl)
+		var tS *ast.TypeSpec
+		if ok && len(gD.Specs) == 1 {
+			tS, ok = gD.Specs[0].(*ast.TypeSpec)
+		}
+		switch {
+		case !ok || tS.Type == nil:
+			err = errors.New("not a type declaration")
+		case tS.TypeParams == nil:
+			err = errors.New("type has no type parameters")
+		default:
+			r.name = tS.Name.Name
+			r.typeParams = fieldsNames(tS.TypeParams)
+		}
+	}
+	if err != nil {
+		r.err = errors.New("invalid generic type declaration " + decl + ": " + err.Error())
+	}
+	return r
+}
+
+func parseGenericDecl(src string) (*ast.File, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), "", "package p\n"+src, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Decls) != 1 {
+		return nil, errors.New("exactly one declaration required")
+	}
+	return f, nil
+}
+
+// fieldsNames returns names of all fields (one name for each type, "" for unnamed fields).
+func fieldsNames(fields *ast.FieldList) (r []string) {
+	for _, field := range fields.List {
+		if len(field.Names) == 0 {
+			r = append(r, "")
+		}
+		for _, name := range field.Names {
+			r = append(r, name.Name)
+		}
+	}
+	return
+}
+
+// fieldsTypes returns types of all fields (one type for each name).
+func fieldsTypes(fields *ast.FieldList) (r []ast.Expr) {
+	for _, field := range fields.List {
+		n := len(field.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			r = append(r, field.Type)
+		}
+	}
+	return
+}
+
+// instantiate instantiates generic with type arguments typeArgs (in addition to already bound type arguments).
+// If not all type parameters are bound then partially instantiated generic function returned.
+func (x genericVal) instantiate(typeArgs []reflect.Type) (r Value, err *intError) {
+	all := make([]reflect.Type, 0, len(x.typeArgs)+len(typeArgs))
+	all = append(append(all, x.typeArgs...), typeArgs...)
+
+	switch {
+	case len(all) > len(x.typeParams):
+		return nil, genericTypeArgsCountError(x.decl, len(x.typeParams), len(all))
+	case len(all) < len(x.typeParams) && x.isType:
+		return nil, genericTypeArgsCountError(x.decl, len(x.typeParams), len(all))
+	case len(all) < len(x.typeParams):
+		x.typeArgs = all
+		return x, nil
+	}
+
+	if x.isType {
+		t, instErr := x.instType(all)
+		switch {
+		case instErr != nil:
+			return nil, genericInstError(x.name, all, instErr)
+		case t == nil:
+			return nil, genericInstError(x.name, all, errors.New("nil type"))
+		}
+		return MakeType(t), nil
+	}
+
+	f, instErr := x.instFunc(all)
+	switch {
+	case instErr != nil:
+		return nil, genericInstError(x.name, all, instErr)
+	case !f.IsValid() || f.Kind() != reflect.Func:
+		return nil, genericInstError(x.name, all, errors.New("not a function"))
+	}
+	return MakeDataRegular(f), nil
+}
+
+// infer infers all unbound type arguments of generic function from types of call arguments and instantiates function.
+// ellipsis true if last argument has ellipsis notation ("f(a,b,c...)").
+func (x genericVal) infer(args []Data, ellipsis bool) (r Value, err *intError) {
+	if x.params == nil {
+		return nil, genericNotInstantiatedError(x)
+	}
+
+	bound := make(map[string]reflect.Type, len(x.typeParams))
+	for i := range x.typeArgs {
+		bound[x.typeParams[i]] = x.typeArgs[i]
+	}
+	u := genericUnifier{typeParams: x.typeParams, bound: bound}
+
+	// paramType returns type of parameter for i-th argument.
+	// slice is true if argument is a slice passed to variadic parameter (with ellipsis).
+	params := fieldsTypes(x.params)
+	paramType := func(i int) (t ast.Expr, slice bool) {
+		last := len(params) - 1
+		if last < 0 {
+			return nil, false
+		}
+		variadic, isVariadic := params[last].(*ast.Ellipsis)
+		switch {
+		case i < last:
+			return params[i], false
+		case isVariadic && ellipsis && i == last:
+			return variadic.Elt, true
+		case isVariadic && !ellipsis:
+			return variadic.Elt, false
+		case !isVariadic && i == last:
+			return params[i], false
+		default:
+			return nil, false
+		}
+	}
+
+	// Typed arguments
+	for i := range args {
+		t, slice := paramType(i)
+		var argT reflect.Type
+		switch args[i].Kind() {
+		case Regular:
+			argT = args[i].Regular().Type()
+		case TypedConst:
+			argT = args[i].TypedConst().Type()
+		}
+		if t == nil || argT == nil {
+			continue
+		}
+		if slice {
+			if argT.Kind() != reflect.Slice {
+				continue
+			}
+			argT = argT.Elem()
+		}
+		if err = u.unify(t, argT); err != nil {
+			return nil, genericInferMismError(x.name, i, err)
+		}
+	}
+
+	// Untyped arguments take default type if parameter type is a type parameter itself
+	for i := range args {
+		t, slice := paramType(i)
+		ident, ok := t.(*ast.Ident)
+		if !ok || slice || !u.isTypeParam(ident.Name) || u.bound[ident.Name] != nil {
+			continue
+		}
+		switch args[i].Kind() {
+		case UntypedConst:
+			u.bound[ident.Name] = constanth.DefaultType(args[i].UntypedConst())
+		case UntypedBool:
+			u.bound[ident.Name] = reflecth.TypeBool()
+		}
+	}
+
+	typeArgs := make([]reflect.Type, 0, len(x.typeParams)-len(x.typeArgs))
+	for _, name := range x.typeParams[len(x.typeArgs):] {
+		t := u.bound[name]
+		if t == nil {
+			return nil, genericCannotInferError(x.name, name)
+		}
+		typeArgs = append(typeArgs, t)
+	}
+	return x.instantiate(typeArgs)
+}
+
+// genericUnifier matches types of generic function parameters with actual types.
+type genericUnifier struct {
+	typeParams []string
+	bound      map[string]reflect.Type
+}
+
+func (u genericUnifier) isTypeParam(name string) bool {
+	for _, p := range u.typeParams {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// unify matches type expression e (which may refer to type parameters) with type t.
+// Parts of e which does not refer to type parameters (and parts with incompatible structure) are skipped here, they are checked on function call.
+func (u genericUnifier) unify(e ast.Expr, t reflect.Type) *intError {
+	switch v := e.(type) {
+	case *ast.Ident:
+		if !u.isTypeParam(v.Name) {
+			return nil
+		}
+		if bound := u.bound[v.Name]; bound != nil && bound != t {
+			return genericInferConflictError(v.Name, bound, t)
+		}
+		u.bound[v.Name] = t
+	case *ast.ParenExpr:
+		return u.unify(v.X, t)
+	case *ast.StarExpr:
+		if t.Kind() == reflect.Ptr {
+			return u.unify(v.X, t.Elem())
+		}
+	case *ast.ArrayType:
+		if (v.Len == nil && t.Kind() == reflect.Slice) || (v.Len != nil && t.Kind() == reflect.Array) {
+			return u.unify(v.Elt, t.Elem())
+		}
+	case *ast.MapType:
+		if t.Kind() == reflect.Map {
+			if err := u.unify(v.Key, t.Key()); err != nil {
+				return err
+			}
+			return u.unify(v.Value, t.Elem())
+		}
+	case *ast.ChanType:
+		if t.Kind() == reflect.Chan {
+			return u.unify(v.Value, t.Elem())
+		}
+	case *ast.FuncType:
+		if t.Kind() != reflect.Func {
+			return nil
+		}
+		var in, out []ast.Expr
+		if v.Params != nil {
+			in = fieldsTypes(v.Params)
+		}
+		if v.Results != nil {
+			out = fieldsTypes(v.Results)
+		}
+		if len(in) != t.NumIn() || len(out) != t.NumOut() {
+			return nil
+		}
+		for i := range in {
+			inT := t.In(i)
+			if ellipsis, ok := in[i].(*ast.Ellipsis); ok {
+				if !t.IsVariadic() || i != len(in)-1 {
+					return nil
+				}
+				in[i], inT = ellipsis.Elt, inT.Elem()
+			}
+			if err := u.unify(in[i], inT); err != nil {
+				return err
+			}
+		}
+		for i := range out {
+			if err := u.unify(out[i], t.Out(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// astGenericInstance instantiates generic x with type arguments specified by indexes ("F[int, string]").
+func (expr *Expression) astGenericInstance(n ast.Node, x genericVal, indexes []ast.Expr, args Args) (r Value, err *posError) {
+	typeArgs := make([]reflect.Type, len(indexes))
+	for i := range indexes {
+		typeArgs[i], err = expr.astExprAsType(indexes[i], args)
+		if err != nil {
+			return
+		}
+	}
+	r, intErr := x.instantiate(typeArgs)
+	err = intErr.pos(n)
+	return
+}
+
+func (expr *Expression) astIndexListExpr(e *ast.IndexListExpr, args Args) (r Value, err *posError) {
+	x, err := expr.astExpr(e.X, args)
+	if err != nil {
+		return
+	}
+	g, ok := x.(genericVal)
+	if !ok {
+		return nil, indexMultipleError(x).pos(e)
+	}
+	return expr.astGenericInstance(e, g, e.Indices, args)
+}
+
+// genericTypeArgsString returns human readable list of type arguments.
+func genericTypeArgsString(typeArgs []reflect.Type) string {
+	s := make([]string, len(typeArgs))
+	for i := range typeArgs {
+		s[i] = typeArgs[i].String()
+	}
+	return "[" + strings.Join(s, ", ") + "]"
+}
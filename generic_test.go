@@ -0,0 +1,133 @@
+package eval
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func genericMap[T, U any](s []T, f func(T) U) []U {
+	r := make([]U, len(s))
+	for i := range s {
+		r[i] = f(s[i])
+	}
+	return r
+}
+
+func genericMax[T int | float64](x T, y ...T) T {
+	for _, v := range y {
+		if v > x {
+			x = v
+		}
+	}
+	return x
+}
+
+type genericPair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+var testGenericArgs = Args{
+	"Map": MakeGenericFunc("Map[T, U any](s []T, f func(T) U) []U", func(typeArgs []reflect.Type) (reflect.Value, error) {
+		switch {
+		case typeArgs[0] == reflect.TypeOf(0) && typeArgs[1] == reflect.TypeOf(""):
+			return reflect.ValueOf(genericMap[int, string]), nil
+		case typeArgs[0] == reflect.TypeOf(0) && typeArgs[1] == reflect.TypeOf(0):
+			return reflect.ValueOf(genericMap[int, int]), nil
+		}
+		return reflect.Value{}, errors.New("unsupported type arguments")
+	}),
+	"Max": MakeGenericFunc("Max[T int | float64](x T, y ...T) T", func(typeArgs []reflect.Type) (reflect.Value, error) {
+		switch typeArgs[0] {
+		case reflect.TypeOf(0):
+			return reflect.ValueOf(genericMax[int]), nil
+		case reflect.TypeOf(0.0):
+			return reflect.ValueOf(genericMax[float64]), nil
+		}
+		return reflect.Value{}, errors.New("unsupported type arguments")
+	}),
+	"Pair": MakeGenericType("Pair[K comparable, V any]", func(typeArgs []reflect.Type) (reflect.Type, error) {
+		if typeArgs[0] == reflect.TypeOf("") && typeArgs[1] == reflect.TypeOf(0) {
+			return reflect.TypeOf(genericPair[string, int]{}), nil
+		}
+		return nil, errors.New("unsupported type arguments")
+	}),
+	"itoa": MakeDataRegularInterface(func(i int) string { return string(rune('a' + i)) }),
+	"s":    MakeDataRegularInterface([]int{0, 1, 2}),
+	"f":    MakeDataRegularInterface([]float64{1.5, 0.5}),
+}
+
+func TestGeneric(t *testing.T) {
+	type testElement struct {
+		expr string
+		r    interface{}
+		err  bool
+	}
+
+	tests := []testElement{
+		{"Map[int, string](s, itoa)", []string{"a", "b", "c"}, false},
+		{"Map[int](s, itoa)", []string{"a", "b", "c"}, false},
+		{"Map(s, itoa)", []string{"a", "b", "c"}, false},
+		{"Map(s, func(x int) int { return x * 2 })", []int{0, 2, 4}, false},
+		{"Map(s, func(x int) float64 { return 1 })", nil, true},
+		{"Map[int, string, int](s, itoa)", nil, true},
+		{"Map[string](s, itoa)", nil, true},
+		{"Map[int]", nil, true},
+		{"Map", nil, true},
+		{"Map(s, 1)", nil, true},
+		{"Max(1, 2, 3)", 3, false},
+		{"Max(1.5, 2)", 2.0, false},
+		{"Max(1, f...)", 1.5, false},
+		{"Max(1, s...)", 2, false},
+		{"Max[float64](1, 2)", 2.0, false},
+		{"Max(1, 2.5)", nil, true},
+		{"Max(s[0], 2.5)", nil, true},
+		{`Max("a")`, nil, true},
+		{"Max()", nil, true},
+		{`Pair[string, int]{"a", 1}.Value`, 1, false},
+		{`Pair[string, int]{"a", 1}`, genericPair[string, int]{"a", 1}, false},
+		{`Pair[string]{"a", 1}`, nil, true},
+		{`Pair[int, int]{1, 1}`, nil, true},
+		{`Pair(1)`, nil, true},
+		{`itoa[int, int]`, nil, true},
+		{`itoa[int]`, nil, true},
+	}
+
+	for _, test := range tests {
+		e, err := ParseString(test.expr, "")
+		if err != nil {
+			t.Fatalf("%v: %v", test.expr, err)
+		}
+		r, err := e.EvalToInterface(testGenericArgs)
+		if err != nil != test.err || !reflect.DeepEqual(r, test.r) {
+			t.Errorf("%v: expect %v %v, got %v %v", test.expr, test.r, test.err, r, err)
+		}
+	}
+}
+
+func TestMakeGenericFunc(t *testing.T) {
+	inst := func([]reflect.Type) (reflect.Value, error) { return reflect.Value{}, nil }
+	for _, decl := range []string{"F(x int)", "F[T any]", "F[T any](x T) {}", "(r R) F[T any]()", "F[T any](x T); var a int"} {
+		e, err := ParseString("1", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = e.EvalRaw(Args{"F": MakeGenericFunc(decl, inst)}); err == nil {
+			t.Errorf("%v: expect error", decl)
+		}
+	}
+}
+
+func TestMakeGenericType(t *testing.T) {
+	inst := func([]reflect.Type) (reflect.Type, error) { return nil, nil }
+	for _, decl := range []string{"T", "T[N]", "T[K any] int"} {
+		e, err := ParseString("1", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = e.EvalRaw(Args{"T": MakeGenericType(decl, inst)}); err == nil {
+			t.Errorf("%v: expect error", decl)
+		}
+	}
+}
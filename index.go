@@ -6,24 +6,30 @@ import (
 )
 
 func indexMap(x reflect.Value, i Data) (r Value, err *intError) {
+	r, _, err = indexMapCommaOk(x, i)
+	return
+}
+
+// indexMapCommaOk is the same as indexMap, but it also reports whether key is present in map.
+func indexMapCommaOk(x reflect.Value, i Data) (r Value, ok bool, err *intError) {
 	if k := x.Kind(); k != reflect.Map {
-		return nil, invIndexOpError(MakeRegular(x), i)
+		return nil, false, invIndexOpError(MakeRegular(x), i)
 	}
 
 	iReqT := x.Type().Key()
 
 	iV, ok := i.Assign(iReqT)
 	if !ok {
-		return nil, convertUnableError(iReqT, i)
+		return nil, false, convertUnableError(iReqT, i)
 	}
 
 	//
 	rV := x.MapIndex(iV)
 	if !rV.IsValid() { // Return zero value if no such key in map
-		return MakeDataRegular(reflect.New(x.Type().Elem()).Elem()), nil
+		return MakeDataRegular(reflect.New(x.Type().Elem()).Elem()), false, nil
 	}
 
-	return MakeDataRegular(rV), nil
+	return MakeDataRegular(rV), true, nil
 }
 
 func indexOther(x reflect.Value, i Data) (r Value, err *intError) {
@@ -36,9 +42,12 @@ func indexOther(x reflect.Value, i Data) (r Value, err *intError) {
 		return nil, convertUnableError(reflect.TypeOf(int(0)), i)
 	}
 
-	// check out-of-range
+	// check out-of-range (constant index of array is checked at compile time in Go)
 	if iInt < 0 || iInt >= x.Len() {
-		return nil, indexOutOfRangeError(iInt)
+		if i.IsConst() && x.Kind() == reflect.Array {
+			return nil, indexOutOfRangeError(iInt)
+		}
+		return nil, indexBoundsError(iInt, x.Len())
 	}
 
 	return MakeDataRegular(x.Index(iInt)), nil
@@ -55,9 +64,12 @@ func indexConstant(x constant.Value, i Data) (r Value, err *intError) {
 	}
 
 	xStr := constant.StringVal(x)
-	// check out-of-range
+	// check out-of-range (constant index of constant string is checked at compile time in Go)
 	if iInt < 0 || iInt >= len(xStr) {
-		return nil, indexOutOfRangeError(iInt)
+		if i.IsConst() {
+			return nil, indexOutOfRangeError(iInt)
+		}
+		return nil, indexBoundsError(iInt, len(xStr))
 	}
 
 	if i.IsConst() {
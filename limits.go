@@ -0,0 +1,187 @@
+package eval
+
+import (
+	"github.com/apaxa-go/helper/strconvh"
+	"go/constant"
+	"math"
+	"reflect"
+)
+
+// NodeLimitError is an underlying error (see Error.Err) of evaluation which exceeds EvalOptions.MaxNodes.
+type NodeLimitError struct {
+	Limit int // number of nodes allowed to evaluate
+}
+
+// Error implements standard error interface.
+func (err NodeLimitError) Error() string {
+	return "evaluation exceeds limit of " + strconvh.FormatInt(err.Limit) + " nodes"
+}
+
+// AllocLimitError is an underlying error (see Error.Err) of evaluation which exceeds EvalOptions.MaxAlloc.
+type AllocLimitError struct {
+	Limit int64 // number of bytes allowed to allocate
+}
+
+// Error implements standard error interface.
+func (err AllocLimitError) Error() string {
+	return "evaluation exceeds allocation limit of " + strconvh.FormatInt64(err.Limit) + " bytes"
+}
+
+// DepthLimitError is an underlying error (see Error.Err) of evaluation which exceeds EvalOptions.MaxDepth.
+type DepthLimitError struct {
+	Limit int // allowed depth of nodes
+}
+
+// Error implements standard error interface.
+func (err DepthLimitError) Error() string {
+	return "evaluation exceeds depth limit of " + strconvh.FormatInt(err.Limit)
+}
+
+// evalBudget stores resources used by single evaluation (see EvalOptions limits).
+type evalBudget struct {
+	nodes int
+	alloc int64
+	depth int
+}
+
+func (opts EvalOptions) limited() bool {
+	return opts.MaxNodes > 0 || opts.MaxAlloc > 0 || opts.MaxDepth > 0
+}
+
+// begin returns expression prepared for single evaluation.
+func (expr *Expression) begin() *Expression {
+	if !expr.opts.limited() {
+		return expr
+	}
+	r := *expr
+	r.budget = &evalBudget{}
+	return &r
+}
+
+// enter accounts evaluation of next node.
+// If it returns nil then exit must be called after node evaluated.
+func (expr *Expression) enter() *intError {
+	b, opts := expr.budget, expr.opts
+	if opts.MaxNodes > 0 && b.nodes >= opts.MaxNodes {
+		return nodeLimitError(opts.MaxNodes)
+	}
+	if opts.MaxDepth > 0 && b.depth >= opts.MaxDepth {
+		return depthLimitError(opts.MaxDepth)
+	}
+	b.nodes++
+	b.depth++
+	return nil
+}
+
+func (expr *Expression) exit() {
+	expr.budget.depth--
+}
+
+// allocate accounts allocation of size bytes.
+func (expr *Expression) allocate(size uint64) *intError {
+	if expr == nil || expr.budget == nil || expr.opts.MaxAlloc <= 0 {
+		return nil
+	}
+	b, limit := expr.budget, uint64(expr.opts.MaxAlloc)
+	if size > limit || uint64(b.alloc)+size > limit {
+		return allocLimitError(expr.opts.MaxAlloc)
+	}
+	b.alloc += int64(size)
+	return nil
+}
+
+// allocateBuiltIn accounts allocation performed by call of built-in function f with arguments args.
+// It must be called before call.
+func (expr *Expression) allocateBuiltIn(f string, args []Value) *intError {
+	if expr == nil || expr.budget == nil || expr.opts.MaxAlloc <= 0 {
+		return nil
+	}
+	switch f {
+	case "new":
+		if len(args) == 1 && args[0].Kind() == Type {
+			return expr.allocate(uint64(args[0].Type().Size()))
+		}
+	case "make":
+		t, n, m, err := builtInMakeArgs(args)
+		if err != nil {
+			return nil // error will be reported by make itself
+		}
+		if m > n {
+			n = m
+		}
+		if n < 0 {
+			n = 0
+		}
+		return expr.allocate(sizeOfElems(t, n))
+	}
+	return nil
+}
+
+// allocateAppend accounts allocation performed by built-in function append.
+// It must be called after call.
+func (expr *Expression) allocateAppend(x Value, r Value) *intError {
+	if expr == nil || expr.budget == nil || expr.opts.MaxAlloc <= 0 {
+		return nil
+	}
+	if x.Kind() != Datas || x.Data().Kind() != Regular || r.Kind() != Datas || r.Data().Kind() != Regular {
+		return nil
+	}
+	xV, rV := x.Data().Regular(), r.Data().Regular()
+	if xV.Kind() != reflect.Slice || rV.Kind() != reflect.Slice || xV.Pointer() == rV.Pointer() {
+		return nil // no new memory allocated
+	}
+	return expr.allocate(sizeOfElems(rV.Type(), rV.Cap()))
+}
+
+// allocateConcat accounts allocation performed by string concatenation x+y.
+// It does nothing if x & y are not strings.
+func (expr *Expression) allocateConcat(x, y Data) *intError {
+	if expr == nil || expr.budget == nil || expr.opts.MaxAlloc <= 0 {
+		return nil
+	}
+	xLen, ok := stringLen(x)
+	if !ok {
+		return nil
+	}
+	yLen, ok := stringLen(y)
+	if !ok {
+		return nil
+	}
+	return expr.allocate(uint64(xLen) + uint64(yLen))
+}
+
+func stringLen(x Data) (int, bool) {
+	switch x.Kind() {
+	case Regular:
+		if x.Regular().Kind() == reflect.String {
+			return x.Regular().Len(), true
+		}
+	case TypedConst:
+		if c := x.TypedConst().Untyped(); c.Kind() == constant.String {
+			return len(constant.StringVal(c)), true
+		}
+	case UntypedConst:
+		if c := x.UntypedConst(); c.Kind() == constant.String {
+			return len(constant.StringVal(c)), true
+		}
+	}
+	return 0, false
+}
+
+// sizeOfElems returns number of bytes required for value of type t with n elements.
+// n is used only for slices, maps and channels.
+func sizeOfElems(t reflect.Type, n int) uint64 {
+	var elemSize uintptr
+	switch t.Kind() {
+	case reflect.Slice, reflect.Chan:
+		elemSize = t.Elem().Size()
+	case reflect.Map:
+		elemSize = t.Key().Size() + t.Elem().Size()
+	default:
+		return uint64(t.Size())
+	}
+	if elemSize != 0 && uint64(n) > math.MaxUint64/uint64(elemSize) {
+		return math.MaxUint64
+	}
+	return uint64(n) * uint64(elemSize)
+}
@@ -0,0 +1,37 @@
+package eval
+
+import (
+	"go/ast"
+)
+
+// multiCall requests all results of top level call (see astMulti).
+type multiCall struct {
+	call *ast.CallExpr
+	r    []Value // results of call
+}
+
+// astMulti evaluates e which may be a call of function with any number of results.
+// All other expressions are evaluated as usual and produce exactly one result.
+// Call is evaluated in the same way as any other node (via astExpr), only results are returned via multiCall.
+func (expr *Expression) astMulti(e ast.Expr, args Args) (r []Value, err *posError) {
+	call, ok := e.(*ast.CallExpr)
+	if !ok || expr.checking() {
+		return expr.astMultiSingle(e, args)
+	}
+
+	m := &multiCall{call: call}
+	multi := *expr
+	multi.multi = m
+	if _, err = multi.astExpr(call, args); err != nil {
+		return
+	}
+	return m.r, nil
+}
+
+func (expr *Expression) astMultiSingle(e ast.Expr, args Args) (r []Value, err *posError) {
+	v, err := expr.astExpr(e, args)
+	if err != nil {
+		return
+	}
+	return []Value{v}, nil
+}
@@ -0,0 +1,50 @@
+package eval
+
+// EvalOptions controls evaluation of expression.
+// The zero value for EvalOptions describes default behaviour.
+type EvalOptions struct {
+	// UnwrapErrors allows to call functions which returns two values, the last of which is of type error ("func(...) (T, error)").
+	// Result of such call is the first value if error is nil.
+	// Otherwise evaluation fails with Error positioned at call which Err field contains error returned by function.
+	UnwrapErrors bool
+
+	// MaxNodes limits number of AST nodes evaluated during single evaluation (0 means no limit).
+	// Evaluation which exceeds limit fails with Error which Err field is of type NodeLimitError.
+	MaxNodes int
+	// MaxAlloc limits estimated number of bytes allocated during single evaluation (0 means no limit).
+	// Allocations performed by built-in functions make, new and append, by composite literals and by string concatenation are counted.
+	// Memory allocated by called functions is not counted.
+	// Evaluation which exceeds limit fails with Error which Err field is of type AllocLimitError.
+	MaxAlloc int64
+	// MaxDepth limits depth of nested AST nodes during evaluation (0 means no limit).
+	// Evaluation which exceeds limit fails with Error which Err field is of type DepthLimitError.
+	MaxDepth int
+
+	// Policy restricts operations which expression may perform on arguments (nil means no restrictions).
+	// See Policy for details.
+	Policy Policy
+
+	// Panics defines how panics in called functions are handled (by default they are converted to errors).
+	// See PanicPolicy for details.
+	Panics PanicPolicy
+
+	// Tracer is called on enter and exit of each evaluated node (nil means no tracing).
+	// See Tracer for details.
+	Tracer Tracer
+}
+
+// WithOptions returns copy of expression which is evaluated with given options.
+// Options apply to all Eval* methods and to Compile (and to resulting Program).
+func (e *Expression) WithOptions(opts EvalOptions) *Expression {
+	r := *e
+	r.opts = opts
+	return &r
+}
+
+// options returns options of expression evaluation.
+func (expr *Expression) options() EvalOptions {
+	if expr == nil {
+		return EvalOptions{}
+	}
+	return expr.opts
+}
@@ -0,0 +1,141 @@
+package eval
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestEvalOptions_UnwrapErrors(t *testing.T) {
+	args := ArgsFromInterfaces(ArgsI{"atoi": strconv.Atoi})
+
+	e, err := ParseString(`atoi("12")+1`, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = e.EvalToInterface(args); err == nil {
+		t.Error("expect error")
+	}
+	r, err := e.WithOptions(EvalOptions{UnwrapErrors: true}).EvalToInterface(args)
+	if err != nil || r != 13 {
+		t.Errorf("expect %v, got %v %v", 13, r, err)
+	}
+
+	// Compiled
+	p, err := e.WithOptions(EvalOptions{UnwrapErrors: true}).Compile(ArgTypes(args))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rV, err := p.Eval(nil)
+	if err != nil || rV.Data().Regular().Interface() != 13 {
+		t.Errorf("expect %v, got %v %v", 13, rV, err)
+	}
+
+	// Error returned by function
+	e, err = ParseString(`1+atoi("x")`, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = e.WithOptions(EvalOptions{UnwrapErrors: true}).EvalToInterface(args)
+	if err == nil {
+		t.Fatal("expect error")
+	}
+	var numErr *strconv.NumError
+	if !errors.As(err, &numErr) || numErr.Func != "Atoi" {
+		t.Errorf("expect wrapped *strconv.NumError, got %#v", err)
+	}
+	if evalErr, ok := err.(Error); !ok || evalErr.Pos.Column != 3 {
+		t.Errorf("expect Error at column 3, got %#v", err)
+	}
+
+	// Only error may be the second result
+	e, err = ParseString(`f()`, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = e.WithOptions(EvalOptions{UnwrapErrors: true}).EvalToInterface(ArgsFromInterfaces(ArgsI{"f": func() (int, int) { return 1, 2 }}))
+	if err == nil {
+		t.Error("expect error")
+	}
+	_, err = e.WithOptions(EvalOptions{UnwrapErrors: true}).EvalToInterface(ArgsFromInterfaces(ArgsI{"f": func() (int, *strconv.NumError) { return 1, nil }}))
+	if err == nil {
+		t.Error("expect error")
+	}
+
+	// Options does not change source expression
+	if !reflect.DeepEqual(e.opts, EvalOptions{}) {
+		t.Error("expect default options")
+	}
+}
+
+func TestEvalOptions_Limits(t *testing.T) {
+	type testElement struct {
+		expr   string
+		opts   EvalOptions
+		errPtr interface{} // pointer to expected error type, nil if no error expected
+		column int         // expected error column
+	}
+
+	args := ArgsFromInterfaces(ArgsI{"s": "abc", "n": 1 << 30, "f": func(x int) int { return x }})
+	tests := []testElement{
+		{"1+2+3", EvalOptions{MaxNodes: 5}, nil, 0},
+		{"1+2+3", EvalOptions{MaxNodes: 4}, &NodeLimitError{}, 5},
+		{"false && 1+2+3 == 6", EvalOptions{MaxNodes: 2}, nil, 0},
+		{"true && 1+2+3 == 6", EvalOptions{MaxNodes: 8}, &NodeLimitError{}, 18},
+		{"f(f(f(1)))", EvalOptions{MaxDepth: 4}, nil, 0},
+		{"f(f(f(f(1))))", EvalOptions{MaxDepth: 4}, &DepthLimitError{}, 7},
+		{"make([]int64, 10)", EvalOptions{MaxAlloc: 80}, nil, 0},
+		{"make([]int64, 10)", EvalOptions{MaxAlloc: 79}, &AllocLimitError{}, 1},
+		{"make([]int64, 1, n)", EvalOptions{MaxAlloc: 1 << 20}, &AllocLimitError{}, 1},
+		{"make([]struct{}, n)", EvalOptions{MaxAlloc: 1}, nil, 0},
+		{"make(map[int32]int32, n)", EvalOptions{MaxAlloc: 1 << 20}, &AllocLimitError{}, 1},
+		{"make(chan int64, n)", EvalOptions{MaxAlloc: 1 << 20}, &AllocLimitError{}, 1},
+		{"*new([1<<20]byte)", EvalOptions{MaxAlloc: 1 << 10}, &AllocLimitError{}, 2},
+		{"append([]int64{}, 1, 2)", EvalOptions{MaxAlloc: 1 << 10}, nil, 0},
+		{"append(make([]byte, 0, 8), s...)", EvalOptions{MaxAlloc: 8}, nil, 0},
+		{"append(make([]byte, 0, 1), s...)", EvalOptions{MaxAlloc: 2}, &AllocLimitError{}, 1},
+		{"[1<<30]int64{}", EvalOptions{MaxAlloc: 1 << 20}, &AllocLimitError{}, 1},
+		{"[]int64{1<<30: 1}", EvalOptions{MaxAlloc: 1 << 20}, &AllocLimitError{}, 1},
+		{"[]int64{1, 2}", EvalOptions{MaxAlloc: 16}, nil, 0},
+		{"map[int64]int64{1: 1, 2: 2}", EvalOptions{MaxAlloc: 31}, &AllocLimitError{}, 1},
+		{"s+s+s", EvalOptions{MaxAlloc: 15}, nil, 0},
+		{"s+s+s", EvalOptions{MaxAlloc: 14}, &AllocLimitError{}, 1},
+	}
+
+	for _, test := range tests {
+		e, err := ParseString(test.expr, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = e.WithOptions(test.opts).EvalToInterface(args)
+		switch {
+		case test.errPtr == nil && err != nil:
+			t.Errorf("%v: unexpected error %v", test.expr, err)
+		case test.errPtr != nil && err == nil:
+			t.Errorf("%v: expect error", test.expr)
+		case test.errPtr != nil:
+			if !errors.As(err, test.errPtr) {
+				t.Errorf("%v: expect %T, got %#v", test.expr, test.errPtr, err)
+			}
+			if evalErr, ok := err.(Error); !ok || evalErr.Pos.Column != test.column {
+				t.Errorf("%v: expect error at column %v, got %v", test.expr, test.column, err)
+			}
+		}
+	}
+
+	// Limits apply to each evaluation separately
+	e, err := ParseString("f(1)+f(2)", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := e.WithOptions(EvalOptions{MaxNodes: 7}).Compile(ArgTypes(args))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if r, err := p.Eval(nil); err != nil || r.Data().Regular().Interface() != 3 {
+			t.Errorf("expect %v, got %v %v", 3, r, err)
+		}
+	}
+}
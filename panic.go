@@ -0,0 +1,69 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+)
+
+// PanicPolicy defines how panics in functions called by expression are handled (see EvalOptions.Panics).
+// Panics in functions passed via args and in custom built-in functions are handled in the same way.
+type PanicPolicy int
+
+// Panic policies.
+const (
+	// PanicToError converts panic to Error positioned at call.
+	// Err field of resulting Error is of type PanicError and contains original panic value and stack trace.
+	PanicToError PanicPolicy = iota
+	// PanicRepanic propagates panic to caller of Eval* method: method panics with original panic value.
+	PanicRepanic
+)
+
+// PanicError describes panic occurred in function called by expression (see PanicToError).
+// If panic value is an error then PanicError wraps it, so its error chain is available via errors.Is and errors.As.
+type PanicError struct {
+	Value interface{} // value passed to panic
+	Stack []byte      // stack trace of goroutine at the moment of panic (in runtime/debug.Stack format)
+}
+
+// Error implements standard error interface.
+func (err PanicError) Error() string {
+	return "panic: " + fmt.Sprint(err.Value)
+}
+
+// Unwrap returns panic value if it is an error.
+func (err PanicError) Unwrap() error {
+	r, _ := err.Value.(error)
+	return r
+}
+
+// repanic is a panic value used to propagate panic of called function to caller of Eval* method (see PanicRepanic).
+type repanic struct {
+	value interface{}
+}
+
+// applyPanicPolicy panics if err is caused by panic in called function and panic should be propagated (see EvalOptions.Panics).
+// Otherwise it returns err as is.
+func (expr *Expression) applyPanicPolicy(err *intError) *intError {
+	if err == nil || expr.options().Panics != PanicRepanic {
+		return err
+	}
+	if p, ok := err.err.(PanicError); ok {
+		panic(repanic{p.Value})
+	}
+	return err
+}
+
+// unwrapRepanic re-panics with original value if rec is a panic propagated by PanicRepanic policy.
+// It is used where panic leaves evaluation (for example in function made from function literal).
+func unwrapRepanic(rec interface{}) {
+	if p, ok := rec.(repanic); ok {
+		panic(p.value)
+	}
+}
+
+// recoveredError converts value recovered at top level of evaluation to error.
+// Panic propagated by PanicRepanic policy is re-panicked with original value, all other panics are bugs.
+func recoveredError(rec interface{}) error {
+	unwrapRepanic(rec)
+	return errors.New(`BUG: unhandled panic "` + fmt.Sprint(rec) + `". Please report bug.`)
+}
@@ -0,0 +1,95 @@
+package eval
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type panicSample struct{ msg string }
+
+func (err *panicSample) Error() string { return err.msg }
+
+func TestEvalOptions_Panics(t *testing.T) {
+	sample := &panicSample{"boom"}
+	args := ArgsFromInterfaces(ArgsI{
+		"f":    func(x int) int { panic(sample) },
+		"g":    func(x int) int { panic(x) },
+		"call": func(h func(int) int, x int) int { return h(x) },
+	})
+
+	type testElement struct {
+		expr  string
+		value interface{}
+	}
+	tests := []testElement{
+		{"f(1) + 1", sample},
+		{"g(5)", 5},
+		{"call(func(x int) int { return g(x) + 1 }, 7)", 7},
+	}
+
+	for _, test := range tests {
+		e, err := ParseString(test.expr, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// PanicToError
+		_, err = e.EvalToInterface(args)
+		var pErr PanicError
+		if !errors.As(err, &pErr) || pErr.Value != test.value || len(pErr.Stack) == 0 || !errors.Is(err, CallPanic) {
+			t.Errorf("%v: expect PanicError with value %v, got %#v", test.expr, test.value, err)
+		}
+
+		// PanicRepanic
+		for _, compiled := range []bool{false, true} {
+			r := func() (rec interface{}) {
+				defer func() { rec = recover() }()
+				e := e.WithOptions(EvalOptions{Panics: PanicRepanic})
+				if !compiled {
+					_, err = e.EvalToInterface(args)
+					return
+				}
+				p, err := e.Compile(ArgTypes(args))
+				if err != nil {
+					t.Fatal(err)
+				}
+				_, err = p.Eval(args)
+				return
+			}()
+			if r != test.value {
+				t.Errorf("%v (compiled %v): expect panic with %v, got %#v %v", test.expr, compiled, test.value, r, err)
+			}
+		}
+	}
+
+	// Error chain of panic value
+	e, err := ParseString("f(1)", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = e.EvalToInterface(args)
+	var sErr *panicSample
+	if !errors.As(err, &sErr) || sErr != sample || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("expect wrapped panic value, got %v", err)
+	}
+
+	// Function made from literal does not leak internal panic values
+	e, err = ParseString("func(x int) int { return g(x) }", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := e.WithOptions(EvalOptions{Panics: PanicRepanic}).EvalToInterface(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := func() (rec interface{}) {
+		defer func() { rec = recover() }()
+		reflect.ValueOf(r).Call([]reflect.Value{reflect.ValueOf(3)})
+		return
+	}()
+	if rec != 3 {
+		t.Errorf("expect panic with %v, got %#v", 3, rec)
+	}
+}
@@ -0,0 +1,192 @@
+package eval
+
+import (
+	"errors"
+	"go/ast"
+	"reflect"
+)
+
+// Policy controls which operations expression may perform on values passed via arguments (see EvalOptions.Policy).
+// Each method is called before performing corresponding operation and returns nil if operation is allowed.
+// Otherwise evaluation fails with Error positioned at operation which Err field is the error returned by Policy.
+// Policy is also consulted by Compile, so operations denied for all values of some types are reported at compile time.
+// Policy applies only to operations performed by expression itself, functions called by expression are not restricted.
+type Policy interface {
+	// Field is called before selecting field name of struct of type t ("x.Name").
+	Field(t reflect.Type, name string) error
+	// Method is called before selecting method name of type t ("x.Name" for method value and "T.Name" for method expression).
+	Method(t reflect.Type, name string) error
+	// Call is called before calling function f.
+	// name is the name of function in arguments: identifier ("f") or qualified identifier for function in package ("pkg.F").
+	// name is empty if called function is not referenced by name (method value, field of function type, function literal, result of call, ...).
+	Call(name string, f reflect.Type) error
+	// Deref is called before dereferencing pointer of type t ("*x").
+	// It is also called before implicit dereference of pointer while selecting field or method with value receiver ("x.Name").
+	Deref(t reflect.Type) error
+	// Recv is called before receiving from channel of type t ("<-x").
+	Recv(t reflect.Type) error
+}
+
+// AllowList is a Policy which allows only explicitly listed operations.
+type AllowList struct {
+	// Members lists names of fields and methods which may be selected, keyed by type.
+	// Members listed for type T are also allowed for pointer type *T.
+	Members map[reflect.Type][]string
+	// Funcs lists names of functions which may be called (in the same form as Policy.Call gets it: "f" or "pkg.F").
+	// Functions without name may always be called because they can be got only via other operations which are checked by policy.
+	Funcs []string
+	// AllowDeref allows to dereference pointers.
+	AllowDeref bool
+	// AllowRecv allows to receive from channels.
+	AllowRecv bool
+}
+
+func (l AllowList) member(t reflect.Type, name string) bool {
+	if contains(l.Members[t], name) {
+		return true
+	}
+	return t.Kind() == reflect.Ptr && contains(l.Members[t.Elem()], name)
+}
+
+// Field implements Policy interface.
+func (l AllowList) Field(t reflect.Type, name string) error {
+	if !l.member(t, name) {
+		return errors.New("field " + name + " of type " + t.String() + " is not allowed")
+	}
+	return nil
+}
+
+// Method implements Policy interface.
+func (l AllowList) Method(t reflect.Type, name string) error {
+	if !l.member(t, name) {
+		return errors.New("method " + name + " of type " + t.String() + " is not allowed")
+	}
+	return nil
+}
+
+// Call implements Policy interface.
+func (l AllowList) Call(name string, f reflect.Type) error {
+	if name != "" && !contains(l.Funcs, name) {
+		return errors.New("call of function " + name + " is not allowed")
+	}
+	return nil
+}
+
+// Deref implements Policy interface.
+func (l AllowList) Deref(t reflect.Type) error {
+	if !l.AllowDeref {
+		return errors.New("dereference of " + t.String() + " is not allowed")
+	}
+	return nil
+}
+
+// Recv implements Policy interface.
+func (l AllowList) Recv(t reflect.Type) error {
+	if !l.AllowRecv {
+		return errors.New("receive from " + t.String() + " is not allowed")
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for i := range list {
+		if list[i] == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (expr *Expression) allowField(t reflect.Type, name string) *intError {
+	if p := expr.options().Policy; p != nil {
+		if err := p.Field(t, name); err != nil {
+			return policyDeniedError(err)
+		}
+	}
+	return nil
+}
+
+func (expr *Expression) allowMethod(t reflect.Type, name string) *intError {
+	if p := expr.options().Policy; p != nil {
+		if err := p.Method(t, name); err != nil {
+			return policyDeniedError(err)
+		}
+	}
+	return nil
+}
+
+// allowCall checks call of function f referenced in expression by fun.
+func (expr *Expression) allowCall(fun ast.Expr, f reflect.Value, args Args) *intError {
+	if p := expr.options().Policy; p != nil {
+		if err := p.Call(expr.funcName(fun, args), f.Type()); err != nil {
+			return policyDeniedError(err)
+		}
+	}
+	return nil
+}
+
+func (expr *Expression) allowDeref(t reflect.Type) *intError {
+	if p := expr.options().Policy; p != nil {
+		if err := p.Deref(t); err != nil {
+			return policyDeniedError(err)
+		}
+	}
+	return nil
+}
+
+// allowImplicitDeref is like allowDeref, but does nothing if t is nil (no pointer is dereferenced).
+func (expr *Expression) allowImplicitDeref(t reflect.Type) *intError {
+	if t == nil {
+		return nil
+	}
+	return expr.allowDeref(t)
+}
+
+func (expr *Expression) allowRecv(t reflect.Type) *intError {
+	if p := expr.options().Policy; p != nil {
+		if err := p.Recv(t); err != nil {
+			return policyDeniedError(err)
+		}
+	}
+	return nil
+}
+
+// funcName returns name of argument which is referenced by e (see Policy.Call).
+func (expr *Expression) funcName(e ast.Expr, args Args) string {
+	switch v := e.(type) {
+	case *ast.ParenExpr:
+		return expr.funcName(v.X, args)
+	case *ast.IndexExpr: // instantiation of generic function
+		if x, ok := expr.lookupArg(v.X, args); ok && x.Kind() == GenericFunc {
+			return expr.funcName(v.X, args)
+		}
+	case *ast.IndexListExpr:
+		if x, ok := expr.lookupArg(v.X, args); ok && x.Kind() == GenericFunc {
+			return expr.funcName(v.X, args)
+		}
+	case *ast.Ident:
+		if _, ok := expr.lookupArg(v, args); ok {
+			return v.Name
+		}
+	case *ast.SelectorExpr:
+		if x, ok := v.X.(*ast.Ident); ok {
+			if pkg, ok := expr.lookupArg(x, args); ok && pkg.Kind() == Package {
+				return x.Name + "." + v.Sel.Name
+			}
+		}
+	}
+	return ""
+}
+
+// lookupArg returns argument referenced by identifier e.
+func (expr *Expression) lookupArg(e ast.Expr, args Args) (r Value, ok bool) {
+	ident, ok := e.(*ast.Ident)
+	if !ok || !isArgIdent(ident) {
+		return nil, false
+	}
+	r, ok = args[ident.Name]
+	if !ok && expr != nil {
+		r, ok = expr.spec[ident.Name]
+	}
+	return
+}
@@ -0,0 +1,90 @@
+package eval
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAllowList(t *testing.T) {
+	type testElement struct {
+		expr   string
+		err    bool
+		column int // expected error column
+	}
+
+	sT := reflect.TypeOf(SampleStruct{})
+	policy := AllowList{
+		Members: map[reflect.Type][]string{sT: {"F", "M"}},
+		Funcs:   []string{"f", "strings.ToUpper"},
+	}
+
+	c := make(chan int, 1)
+	c <- 1
+	args := ArgsFromInterfaces(ArgsI{
+		"a": SampleStruct{2},
+		"p": &SampleStruct{3},
+		"c": c,
+		"f": func(x int) int { return x },
+		"g": func(x int) int { return x },
+	})
+	args["strings"] = MakePackage(ArgsFromInterfaces(ArgsI{"ToUpper": strings.ToUpper, "ToLower": strings.ToLower}))
+	args["S"] = MakeType(sT)
+
+	tests := []testElement{
+		{"a.F", false, 0},
+		{"p.F", true, 1},
+		{"a.M(2)", false, 0},
+		{"p.M(2)", true, 1},
+		{"S.M(a, 2)", false, 0},
+		{"a.M2(2)", true, 1},
+		{"p.M2", true, 1},
+		{"S.M2", true, 1},
+		{"f(1)", false, 0},
+		{"(f)(1)", false, 0},
+		{"1+g(1)", true, 3},
+		{"false && g(1) == 1", false, 0},
+		{"true || p.F == 1", false, 0},
+		{`strings.ToUpper("a")`, false, 0},
+		{`strings.ToLower("A")`, true, 1},
+		{"func(x int) int { return g(x) }(1)", true, 26},
+		{"func(x int) int { return f(x) }(1)", false, 0},
+		{"(*p).F", true, 2},
+		{"<-c", true, 1},
+	}
+
+	for _, test := range tests {
+		e, err := ParseString(test.expr, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		e = e.WithOptions(EvalOptions{Policy: policy})
+		_, err = e.EvalToInterface(args)
+		if err != nil != test.err {
+			t.Errorf("%v: expect error %v, got %v", test.expr, test.err, err)
+			continue
+		}
+		if evalErr, ok := err.(Error); test.err && (!ok || evalErr.Pos.Column != test.column) {
+			t.Errorf("%v: expect error at column %v, got %v", test.expr, test.column, err)
+		}
+
+		// The same must be detected at compile time
+		_, err = e.Compile(ArgTypes(args))
+		if err != nil != test.err {
+			t.Errorf("%v: expect compile error %v, got %v", test.expr, test.err, err)
+		}
+	}
+
+	// Dereference & receive
+	policy.AllowDeref = true
+	policy.AllowRecv = true
+	for _, s := range []string{"(*p).F", "p.F", "p.M(2)", "<-c"} {
+		e, err := ParseString(s, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = e.WithOptions(EvalOptions{Policy: policy}).EvalToInterface(args); err != nil {
+			t.Errorf("%v: unexpected error %v", s, err)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package eval
+
+import (
+	"bytes"
+	"go/ast"
+	"go/constant"
+	"go/printer"
+	"go/token"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+// String returns source of expression (see Format).
+func (e *Expression) String() string {
+	var buf bytes.Buffer
+	if err := e.Format(&buf); err != nil {
+		return "<invalid expression: " + err.Error() + ">"
+	}
+	return buf.String()
+}
+
+// Format writes source of expression to w.
+// Source is printed by go/printer with normalized spacing and minimal parentheses (redundant parentheses are removed, required ones are kept).
+// Result does not depend on formatting of original source, so it may be used to show expressions built via MakeExpression or modified via Simplify.
+// Function literals are printed in multiple lines (as gofmt does).
+func (e *Expression) Format(w io.Writer) error {
+	_, err := e.WriteTo(w)
+	return err
+}
+
+// WriteTo writes source of expression to w like Format does, but also returns number of bytes written.
+// WriteTo implements io.WriterTo, so expression may be formatted directly to any writer.
+func (e *Expression) WriteTo(w io.Writer) (n int64, err error) {
+	return writeExpr(w, printableExpr(e.e, false))
+}
+
+// Canonical returns canonical form of expression.
+// It is the same as String, but literals are also normalized: integers are printed in decimal notation ("0x10" and "1_6" are printed as "16"), strings and characters are printed quoted by strconv ("`a`" is printed as "\"a\"").
+// Syntactically equal expressions have equal canonical forms, so it may be used for hashing and equality checks.
+func (e *Expression) Canonical() string {
+	var buf bytes.Buffer
+	if _, err := writeExpr(&buf, printableExpr(e.e, true)); err != nil {
+		return "<invalid expression: " + err.Error() + ">"
+	}
+	return buf.String()
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countWriter) Write(p []byte) (n int, err error) {
+	n, err = w.w.Write(p)
+	w.n += int64(n)
+	return
+}
+
+func writeExpr(w io.Writer, e ast.Expr) (n int64, err error) {
+	cw := &countWriter{w: w}
+	err = printer.Fprint(cw, token.NewFileSet(), e)
+	return cw.n, err
+}
+
+// printableExpr returns copy of e without positions and redundant parentheses.
+// If canonical is true then literals are normalized.
+func printableExpr(e ast.Expr, canonical bool) ast.Expr {
+	r, _ := printableNode(reflect.ValueOf(&e).Elem(), canonical).Interface().(ast.Expr)
+	return r
+}
+
+func printableNode(x reflect.Value, canonical bool) reflect.Value {
+	switch x.Kind() {
+	case reflect.Interface:
+		if x.IsNil() {
+			return x
+		}
+		r := reflect.New(x.Type()).Elem()
+		r.Set(printableNode(x.Elem(), canonical))
+		return r
+	case reflect.Ptr:
+		if x.IsNil() {
+			return x
+		}
+		if paren, ok := x.Interface().(*ast.ParenExpr); ok {
+			return printableNode(reflect.ValueOf(paren.X), canonical)
+		}
+		// Only fields from encoding schema are copied, so objects and comments are dropped
+		r := reflect.New(x.Type().Elem())
+		rFields := nodeFields(r.Interface().(ast.Node))
+		for i, f := range nodeFields(x.Interface().(ast.Node)) {
+			field, rField := reflect.ValueOf(f.ptr).Elem(), reflect.ValueOf(rFields[i].ptr).Elem()
+			switch field.Type() {
+			case posType:
+				if field.Interface().(token.Pos).IsValid() {
+					rField.Set(reflect.ValueOf(token.Pos(1))) // valid positions are meaningful (for example, ellipsis in call), but theirs values are not
+				}
+			default:
+				rField.Set(printableNode(field, canonical))
+			}
+		}
+		node := r.Interface()
+		if lit, ok := node.(*ast.BasicLit); ok && canonical {
+			lit.Value = canonicalLiteral(lit)
+		}
+		parenthesize(node)
+		return r
+	case reflect.Slice:
+		if x.IsNil() {
+			return x
+		}
+		r := reflect.MakeSlice(x.Type(), x.Len(), x.Len())
+		for i := 0; i < x.Len(); i++ {
+			r.Index(i).Set(printableNode(x.Index(i), canonical))
+		}
+		return r
+	default:
+		return x
+	}
+}
+
+// parenthesize adds parentheses which are required, but are not added by go/printer.
+func parenthesize(n interface{}) {
+	switch v := n.(type) {
+	case *ast.StarExpr:
+		if _, ok := v.X.(*ast.BinaryExpr); ok {
+			v.X = &ast.ParenExpr{X: v.X}
+		}
+	case *ast.CallExpr:
+		if _, ok := v.Fun.(*ast.ChanType); ok {
+			v.Fun = &ast.ParenExpr{X: v.Fun}
+		}
+	}
+}
+
+// canonicalLiteral returns normalized representation of literal x.
+func canonicalLiteral(x *ast.BasicLit) string {
+	c := constant.MakeFromLiteral(x.Value, x.Kind, 0)
+	switch {
+	case c.Kind() == constant.Unknown:
+		return x.Value
+	case x.Kind == token.INT:
+		return c.ExactString()
+	case x.Kind == token.STRING:
+		return strconv.Quote(constant.StringVal(c))
+	case x.Kind == token.CHAR:
+		r, _ := constant.Int64Val(c)
+		return strconv.QuoteRune(rune(r))
+	case x.Kind == token.IMAG:
+		if lit, ok := constLiteral(constant.Imag(c), token.NoPos); ok {
+			if lit, ok := lit.(*ast.BasicLit); ok {
+				return lit.Value + "i"
+			}
+		}
+		return x.Value
+	default: // float
+		if lit, ok := constLiteral(c, token.NoPos); ok {
+			if lit, ok := lit.(*ast.BasicLit); ok {
+				return lit.Value
+			}
+		}
+		return x.Value
+	}
+}
@@ -0,0 +1,74 @@
+package eval
+
+import (
+	"bytes"
+	"go/ast"
+	"go/token"
+	"testing"
+)
+
+func TestExpression_String(t *testing.T) {
+	type testElement struct {
+		expr      string
+		r         string
+		canonical string
+	}
+
+	tests := []testElement{
+		{"((a))+(b*c)", "a + b*c", "a + b*c"},
+		{"(a+b)*c", "(a + b) * c", "(a + b) * c"},
+		{"a-(b-c)", "a - (b - c)", "a - (b - c)"},
+		{"*(p+q)", "*(p + q)", "*(p + q)"},
+		{"(*T)(x)", "(*T)(x)", "(*T)(x)"},
+		{"(*T).M", "(*T).M", "(*T).M"},
+		{"(chan int)(nil)", "(chan int)(nil)", "(chan int)(nil)"},
+		{"(func())(nil)", "(func())(nil)", "(func())(nil)"},
+		{"-(-x)", "- -x", "- -x"},
+		{"(<-ch).F", "(<-ch).F", "(<-ch).F"},
+		{"f( a ,b... )", "f(a, b...)", "f(a, b...)"},
+		{"0x10+1_6", "0x10 + 1_6", "16 + 16"},
+		{"`a`+\"\\x62\"", "`a` + \"\\x62\"", "\"a\" + \"b\""},
+		{"'\\x41'", "'\\x41'", "'A'"},
+		{"1.50 + 2e1i", "1.50 + 2e1i", "1.5 + 20.0i"},
+		{"[]int{1, (2)}[0]", "[]int{1, 2}[0]", "[]int{1, 2}[0]"},
+	}
+
+	for _, test := range tests {
+		e, err := ParseString(test.expr, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r := e.String(); r != test.r {
+			t.Errorf("%v: expect %v, got %v", test.expr, test.r, r)
+		}
+		if r := e.Canonical(); r != test.canonical {
+			t.Errorf("%v: expect canonical %v, got %v", test.expr, test.canonical, r)
+		}
+		// printed source must be parsed to the same expression
+		e2, err := ParseString(test.r, "")
+		if err != nil {
+			t.Errorf("%v: unable to parse printed source: %v", test.expr, err)
+		} else if e2.Canonical() != e.Canonical() {
+			t.Errorf("%v: reparsed expression differs: %v", test.expr, e2.Canonical())
+		}
+	}
+
+	// Expression built without source
+	x := &ast.BinaryExpr{
+		X:  &ast.BinaryExpr{X: ast.NewIdent("a"), Op: token.ADD, Y: ast.NewIdent("b")},
+		Op: token.MUL,
+		Y:  &ast.BasicLit{Kind: token.INT, Value: "2"},
+	}
+	e := MakeExpression(x, token.NewFileSet(), "")
+	if r := e.String(); r != "(a + b) * 2" {
+		t.Errorf("expect %v, got %v", "(a + b) * 2", r)
+	}
+	var buf bytes.Buffer
+	if n, err := e.WriteTo(&buf); err != nil || n != int64(buf.Len()) || buf.String() != "(a + b) * 2" {
+		t.Errorf("unexpected WriteTo result: %v %v %v", n, err, buf.String())
+	}
+	buf.Reset()
+	if err := e.Format(&buf); err != nil || buf.String() != "(a + b) * 2" {
+		t.Errorf("unexpected Format result: %v %v", err, buf.String())
+	}
+}
@@ -0,0 +1,187 @@
+package eval
+
+import (
+	"errors"
+	"go/ast"
+	"reflect"
+	"strings"
+)
+
+// ArgTypes describes arguments which are available to expression while compiling (see Expression.Compile).
+// It has the same format as Args.
+// Types, typed & untyped constants and packages are fixed at compile time.
+// For regular variables and untyped boolean variables only theirs types are used at compile time, actual values are passed to Program.Eval.
+// If such variable is not passed to Program.Eval then value stored in ArgTypes is used.
+type ArgTypes Args
+
+// ArgTypesFromTypes makes ArgTypes which declares regular variables of types x.
+// All of such variables are initialized with zero values (see ArgTypes for more details).
+func ArgTypesFromTypes(x map[string]reflect.Type) ArgTypes {
+	r := make(ArgTypes, len(x))
+	for i := range x {
+		r[i] = MakeDataRegular(reflect.New(x[i]).Elem())
+	}
+	return r
+}
+
+// Program is a compiled expression.
+// All types used in expression are already resolved, constant subexpressions are already computed and expression is already checked for type errors.
+// Program may be evaluated multiple times (even concurrently) with different values of variables.
+type Program struct {
+	expr Expression
+}
+
+// Compile checks expression against arguments described by spec and computes all which does not depend on values of variables.
+// It returns error if expression can not be evaluated with arguments of specified types.
+// Errors which depend on values of variables (index out of range, panic in called function, ...) are reported by Program.Eval.
+// Compile does not call any functions passed via spec and does not receive from channels.
+func (e *Expression) Compile(spec ArgTypes) (p *Program, err error) {
+	defer func() {
+		rec := recover()
+		if rec != nil {
+			p = nil
+			err = recoveredError(rec)
+		}
+	}()
+
+	state := &checkState{folded: make(map[ast.Expr]Value)}
+	args, _, err := e.checkSpec(spec, state)
+	if err != nil {
+		return
+	}
+
+	p = &Program{expr: *e}
+	p.expr.spec = args
+	p.expr.folded = state.folded
+	return
+}
+
+// checkSpec checks expression against arguments described by spec collecting information in state.
+// It returns normalized arguments and result of check.
+func (e *Expression) checkSpec(spec ArgTypes, state *checkState) (args Args, r Value, err error) {
+	args = make(Args, len(spec))
+	for ident, arg := range spec {
+		args[ident] = arg
+	}
+
+	err = args.validate()
+	if err != nil {
+		return
+	}
+
+	args.makeAddressable()
+
+	err = args.normalize()
+	if err != nil {
+		return
+	}
+
+	check := *e
+	check.check = state
+	r, posErr := check.begin().astExpr(e.e, args)
+	if posErr != nil {
+		return nil, nil, posErr.error(e.fset)
+	}
+	return
+}
+
+// Eval evaluates compiled expression with given values of variables args.
+// All args must be regular or untyped boolean variables declared at compile time with the same types.
+// Variables in packages are passed in the same form as they are declared ("pkg.Var").
+// Missing variables take values specified at compile time.
+// Result of evaluation is Value.
+func (p *Program) Eval(args Args) (r Value, err error) {
+	defer func() {
+		rec := recover()
+		if rec != nil {
+			err = recoveredError(rec)
+		}
+	}()
+
+	args, err = p.prepareArgs(args)
+	if err != nil {
+		return
+	}
+
+	var posErr *posError
+	r, posErr = p.expr.begin().astExpr(p.expr.e, args)
+	err = posErr.error(p.expr.fset)
+	return
+}
+
+// prepareArgs returns normalized copy of args checked against arguments declared at compile time.
+// Packages in result contain all members declared at compile time (with values overridden by args).
+// Arguments declared at compile time are already validated and normalized, so each arg is only checked against its declaration.
+func (p *Program) prepareArgs(args Args) (r Args, err error) {
+	if len(args) == 0 {
+		return nil, nil // all values are taken from spec
+	}
+
+	r = make(Args, len(args))
+	for ident, arg := range args {
+		pkg, name := ident, ""
+		if i := strings.IndexByte(ident, '.'); i >= 0 {
+			pkg, name = ident[:i], ident[i+1:]
+		}
+		spec, ok := p.expr.spec[pkg]
+
+		switch {
+		case name == "" && arg.Kind() != Package:
+			if err = validateArg(ident, spec, ok, arg); err != nil {
+				return
+			}
+			r[ident] = addressableValue(arg)
+			continue
+		case !ok || spec.Kind() != Package:
+			return nil, errors.New(pkg + ": not a package declared at compile time")
+		}
+
+		members, ok := r[pkg]
+		if !ok {
+			members = MakePackage(make(Args, len(spec.Package())))
+			for memberName, member := range spec.Package() {
+				members.Package()[memberName] = member
+			}
+			r[pkg] = members
+		}
+		if name != "" {
+			specMember, ok := spec.Package()[name]
+			if err = validateArg(ident, specMember, ok, arg); err != nil {
+				return
+			}
+			members.Package()[name] = addressableValue(arg)
+			continue
+		}
+		for memberName, member := range arg.Package() {
+			specMember, ok := spec.Package()[memberName]
+			if err = validateArg(ident+"."+memberName, specMember, ok, member); err != nil {
+				return
+			}
+			members.Package()[memberName] = addressableValue(member)
+		}
+	}
+	return
+}
+
+// validateArg checks what arg matches argument spec declared at compile time.
+// ok is false if argument is not declared.
+func validateArg(ident string, spec Value, ok bool, arg Value) error {
+	if !ok || !isVariable(spec) {
+		return errors.New(ident + ": not a variable declared at compile time")
+	}
+	if !isVariable(arg) {
+		return errors.New(ident + ": " + arg.DeepType() + " is not a variable")
+	}
+	if arg.Data().Kind() == Regular && !arg.Data().Regular().IsValid() {
+		return errors.New(ident + ": invalid regular data")
+	}
+
+	specD, argD := spec.Data(), arg.Data()
+	switch {
+	case specD.Kind() == Regular && argD.Kind() == Regular && specD.Regular().Type() == argD.Regular().Type():
+	case specD.Kind() == UntypedBool && argD.Kind() == UntypedBool:
+	default:
+		return errors.New(ident + ": type " + argD.DeepType() + " differs from declared type " + specD.DeepType())
+	}
+	return nil
+}
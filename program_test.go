@@ -0,0 +1,195 @@
+package eval
+
+import (
+	"errors"
+	"github.com/apaxa-go/helper/reflecth"
+	"go/ast"
+	"go/constant"
+	"reflect"
+	"testing"
+)
+
+func TestExpression_Compile(t *testing.T) {
+	for section := range testsExpr {
+		for _, test := range testsExpr[section] {
+			expr, err := ParseString(test.expr, "")
+			if err != nil {
+				t.Errorf("%v: %v", test.expr, err)
+				continue
+			}
+
+			var r Value
+			p, err := expr.Compile(ArgTypes(test.vars))
+			if err == nil {
+				r, err = p.Eval(nil)
+			}
+			if !test.Validate(r, err) {
+				t.Error(test.ErrorMsg(r, err))
+			}
+		}
+	}
+}
+
+func TestExpression_Compile2(t *testing.T) {
+	type testElement struct {
+		expr string
+		spec ArgTypes
+		err  bool
+	}
+
+	c := make(chan int)
+	tests := []testElement{
+		{"a+1", ArgTypesFromTypes(map[string]reflect.Type{"a": reflecth.TypeInt()}), false},
+		{`a+"1"`, ArgTypesFromTypes(map[string]reflect.Type{"a": reflecth.TypeInt()}), true},
+		{"a/0", ArgTypesFromTypes(map[string]reflect.Type{"a": reflecth.TypeFloat64()}), false},
+		{"f(1)", ArgTypes{"f": MakeDataRegularInterface(func(int) int { panic("must not be called") })}, false},
+		{`f("1")`, ArgTypes{"f": MakeDataRegularInterface(func(int) int { panic("must not be called") })}, true},
+		{"<-c", ArgTypes{"c": MakeDataRegularInterface(c)}, false},
+		{"a[5]", ArgTypesFromTypes(map[string]reflect.Type{"a": reflect.TypeOf([]int{})}), false},
+		{"a[5]", ArgTypesFromTypes(map[string]reflect.Type{"a": reflect.TypeOf([2]int{})}), true},
+		{"a[i]", ArgTypesFromTypes(map[string]reflect.Type{"a": reflect.TypeOf([1]int{}), "i": reflecth.TypeInt()}), false},
+		{`"a"[i]`, ArgTypesFromTypes(map[string]reflect.Type{"i": reflecth.TypeInt()}), false},
+		{"a[1:5]", ArgTypesFromTypes(map[string]reflect.Type{"a": reflect.TypeOf([]int{})}), false},
+		{"a[1:5:7]", ArgTypesFromTypes(map[string]reflect.Type{"a": reflecth.TypeString()}), true},
+		{"make([]byte, 1<<40)", nil, false},
+		{"make([]byte, 2, 1)", nil, true},
+		{"x.(int)", ArgTypesFromTypes(map[string]reflect.Type{"x": reflecth.TypeEmptyInterface()}), false},
+		{"x.MyMethod", ArgTypesFromTypes(map[string]reflect.Type{"x": reflect.TypeOf((*myInterface)(nil)).Elem()}), false},
+		{"x.Unknown", ArgTypesFromTypes(map[string]reflect.Type{"x": reflect.TypeOf((*myInterface)(nil)).Elem()}), true},
+		{"x.F+(*x).F", ArgTypesFromTypes(map[string]reflect.Type{"x": reflect.TypeOf(&SampleStruct{})}), false},
+		{"y", nil, true},
+		// Validity of these operations depends on values known only at run time
+		{"a/(b-1)", ArgTypesFromTypes(map[string]reflect.Type{"a": reflecth.TypeInt(), "b": reflecth.TypeInt()}), false},
+		{"a%(b-1)", ArgTypesFromTypes(map[string]reflect.Type{"a": reflecth.TypeInt(), "b": reflecth.TypeInt()}), false},
+		{"a/(b-1)", ArgTypesFromTypes(map[string]reflect.Type{"a": reflecth.TypeUint(), "b": reflecth.TypeUint()}), false},
+		{"a/0", ArgTypesFromTypes(map[string]reflect.Type{"a": reflecth.TypeInt()}), true},
+		{"a<<(n-2)", ArgTypesFromTypes(map[string]reflect.Type{"a": reflecth.TypeInt(), "n": reflecth.TypeUint()}), false},
+		{"1>>(n-2)", ArgTypesFromTypes(map[string]reflect.Type{"n": reflecth.TypeUint()}), false},
+		{"a<<n", ArgTypesFromTypes(map[string]reflect.Type{"a": reflecth.TypeInt(), "n": reflecth.TypeInt()}), true},
+		{"a<<-1", ArgTypesFromTypes(map[string]reflect.Type{"a": reflecth.TypeInt()}), true},
+		{"make([]int, n-2)", ArgTypesFromTypes(map[string]reflect.Type{"n": reflecth.TypeInt()}), false},
+		{"make([]int, 1, n-2)", ArgTypesFromTypes(map[string]reflect.Type{"n": reflecth.TypeInt()}), false},
+		{"make(map[int]int, n-2)", ArgTypesFromTypes(map[string]reflect.Type{"n": reflecth.TypeInt()}), false},
+		{"make(chan int, n-2)", ArgTypesFromTypes(map[string]reflect.Type{"n": reflecth.TypeInt()}), false},
+		{"make([]int, -1)", nil, true},
+	}
+
+	for _, test := range tests {
+		expr, err := ParseString(test.expr, "")
+		if err != nil {
+			t.Fatalf("%v: %v", test.expr, err)
+		}
+		_, err = expr.Compile(test.spec)
+		if err != nil != test.err {
+			t.Errorf("%v: expect error %v, got %v", test.expr, test.err, err)
+		}
+	}
+
+	// Values are checked at run time
+	expr, err := ParseString("a/(b-1)", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := expr.Compile(ArgTypesFromTypes(map[string]reflect.Type{"a": reflecth.TypeInt(), "b": reflecth.TypeInt()}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = p.Eval(ArgsFromInterfaces(ArgsI{"a": 1, "b": 1})); !errors.Is(err, DivisionByZero) {
+		t.Errorf("expect division by zero, got %v", err)
+	}
+	if r, err := p.Eval(ArgsFromInterfaces(ArgsI{"a": 6, "b": 3})); err != nil || r.Data().Regular().Interface() != 3 {
+		t.Errorf("expect %v, got %v %v", 3, r, err)
+	}
+}
+
+func TestExpression_Compile3(t *testing.T) {
+	expr, err := ParseString(`struct{A int}{A: 1+2}.A*a`, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := expr.Compile(ArgTypesFromTypes(map[string]reflect.Type{"a": reflecth.TypeInt()}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Check what constant & type subexpressions are folded
+	folded := 0
+	for e := range p.expr.folded {
+		switch e.(type) {
+		case *ast.StructType, *ast.BinaryExpr:
+			folded++
+		}
+	}
+	if folded != 2 {
+		t.Errorf("expect 2 folded nodes, got %v", folded)
+	}
+
+	for i := 0; i < 3; i++ {
+		r, err := p.Eval(ArgsFromInterfaces(ArgsI{"a": i}))
+		if err != nil || r.Data().Regular().Interface() != 3*i {
+			t.Errorf("expect %v, got %v %v", 3*i, r, err)
+		}
+	}
+
+	// Default value
+	r, err := p.Eval(nil)
+	if err != nil || r.Data().Regular().Interface() != 0 {
+		t.Errorf("expect %v, got %v %v", 0, r, err)
+	}
+
+	// Invalid arguments
+	if _, err = p.Eval(ArgsFromInterfaces(ArgsI{"a": int8(1)})); err == nil {
+		t.Error("expect error")
+	}
+	if _, err = p.Eval(ArgsFromInterfaces(ArgsI{"b": 1})); err == nil {
+		t.Error("expect error")
+	}
+	if _, err = p.Eval(Args{"a": MakeTypeInterface(1)}); err == nil {
+		t.Error("expect error")
+	}
+}
+
+func TestProgram_EvalPackageVar(t *testing.T) {
+	expr, err := ParseString(`pkg.Var*pkg.Const + a`, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := ArgTypes{
+		"pkg.Var":   MakeDataRegularInterface(1),
+		"pkg.Const": MakeDataUntypedConst(constant.MakeInt64(10)),
+		"a":         MakeDataRegularInterface(0),
+	}
+	p, err := expr.Compile(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	args := ArgsFromInterfaces(ArgsI{"pkg.Var": 5})
+	r, err := p.Eval(args)
+	if err != nil || r.Data().Regular().Interface() != 50 {
+		t.Errorf("expect %v, got %v %v", 50, r, err)
+	}
+	if _, ok := args["pkg.Var"]; !ok || len(args) != 1 {
+		t.Error("Eval must not modify args")
+	}
+	if r, err = p.Eval(nil); err != nil || r.Data().Regular().Interface() != 10 {
+		t.Errorf("expect %v, got %v %v", 10, r, err)
+	}
+	if r, err = p.Eval(Args{"pkg": MakePackage(ArgsFromInterfaces(ArgsI{"Var": 2}))}); err != nil || r.Data().Regular().Interface() != 20 {
+		t.Errorf("expect %v, got %v %v", 20, r, err)
+	}
+
+	// Invalid arguments
+	for _, args := range []Args{
+		ArgsFromInterfaces(ArgsI{"pkg.Var": int8(5)}),
+		ArgsFromInterfaces(ArgsI{"pkg.Const": 5}),
+		ArgsFromInterfaces(ArgsI{"pkg.Other": 5}),
+		ArgsFromInterfaces(ArgsI{"a.Var": 5}),
+		{"pkg.Var": MakeDataRegular(reflect.Value{})},
+		{"pkg": MakePackage(ArgsFromInterfaces(ArgsI{"Other": 5}))},
+	} {
+		if _, err = p.Eval(args); err == nil {
+			t.Errorf("%v: expect error", args)
+		}
+	}
+}
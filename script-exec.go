@@ -0,0 +1,612 @@
+package eval
+
+import (
+	"github.com/apaxa-go/helper/goh/constanth"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"reflect"
+	"unicode/utf8"
+)
+
+// scriptCtl describes how execution continues after statement.
+type scriptCtl int
+
+const (
+	ctlNext     scriptCtl = iota // continue with next statement
+	ctlBreak                     // break statement executed
+	ctlContinue                  // continue statement executed
+	ctlReturn                    // return statement executed
+)
+
+// scriptBlock is a scope of block of script.
+// Block shares args with parent block until first variable is declared in it.
+type scriptBlock struct {
+	args Args
+	decl map[string]bool // variables declared in this block, nil if args is shared with parent block
+}
+
+func (b *scriptBlock) child() *scriptBlock {
+	return &scriptBlock{args: b.args}
+}
+
+// declare declares new variable with value v in block.
+func (b *scriptBlock) declare(name string, v reflect.Value) {
+	if name == "_" {
+		return
+	}
+	if b.decl == nil {
+		args := make(Args, len(b.args)+1)
+		for ident, arg := range b.args {
+			args[ident] = arg
+		}
+		b.args = args
+		b.decl = make(map[string]bool)
+	}
+	b.args[name] = MakeDataRegular(v)
+	b.decl[name] = true
+}
+
+// renew replaces all variables declared in block with theirs copies (used for per-iteration variables of for statement).
+// Args are copied because previous variables may be captured by function literals.
+func (b *scriptBlock) renew() {
+	if b.decl == nil {
+		return
+	}
+	args := make(Args, len(b.args))
+	for ident, arg := range b.args {
+		args[ident] = arg
+	}
+	for name := range b.decl {
+		args[name] = MakeDataRegular(copyValues([]reflect.Value{args[name].Data().Regular()})[0])
+	}
+	b.args = args
+}
+
+// scriptLoc is an assignable location: variable (or any other settable value), map element or blank identifier.
+type scriptLoc struct {
+	v    reflect.Value // settable value
+	m, k reflect.Value // map & key for map element
+}
+
+func (l scriptLoc) blank() bool { return !l.v.IsValid() && !l.m.IsValid() }
+
+func (l scriptLoc) get() Data {
+	if !l.m.IsValid() {
+		return MakeRegular(l.v)
+	}
+	if r := l.m.MapIndex(l.k); r.IsValid() {
+		return MakeRegular(r)
+	}
+	return MakeRegular(reflect.Zero(l.m.Type().Elem()))
+}
+
+func (l scriptLoc) set(x Data) *intError {
+	switch {
+	case l.m.IsValid():
+		if l.m.IsNil() {
+			return nilMapError()
+		}
+		v, ok := x.Assign(l.m.Type().Elem())
+		if !ok {
+			return assignTypesMismError(l.m.Type().Elem(), x)
+		}
+		l.m.SetMapIndex(l.k, v)
+		return nil
+	case l.v.IsValid():
+		return assign(l.v, x)
+	default:
+		if x.Kind() == Nil {
+			return scriptUntypedNilError()
+		}
+		return nil
+	}
+}
+
+func (expr *Expression) execScript(body *ast.BlockStmt, args Args) (r Value, err *posError) {
+	_, r, err = expr.execStmts(body.List, &scriptBlock{args: args})
+	return
+}
+
+func (expr *Expression) execStmts(list []ast.Stmt, b *scriptBlock) (ctl scriptCtl, r Value, err *posError) {
+	for _, s := range list {
+		ctl, r, err = expr.execStmt(s, b)
+		if err != nil || ctl != ctlNext {
+			return
+		}
+	}
+	return
+}
+
+// tick accounts single step of execution (statement or loop iteration) at node n.
+func (expr *Expression) tick(n ast.Node) *posError {
+	if expr.budget != nil {
+		if err := expr.enter(); err != nil {
+			return err.pos(n)
+		}
+		expr.exit()
+	}
+	return expr.contextError().pos(n)
+}
+
+func (expr *Expression) execStmt(s ast.Stmt, b *scriptBlock) (ctl scriptCtl, r Value, err *posError) {
+	if err = expr.tick(s); err != nil {
+		return
+	}
+	if tracer := expr.options().Tracer; tracer != nil && s != nil {
+		return expr.traceStmt(tracer, s, b)
+	}
+	return expr.execStmtNode(s, b)
+}
+
+func (expr *Expression) execStmtNode(s ast.Stmt, b *scriptBlock) (ctl scriptCtl, r Value, err *posError) {
+	switch v := s.(type) {
+	case nil, *ast.EmptyStmt:
+	case *ast.BlockStmt:
+		return expr.execStmts(v.List, b.child())
+	case *ast.ExprStmt:
+		_, err = expr.astMulti(unparen(v.X), b.args)
+	case *ast.AssignStmt:
+		err = expr.execAssign(v, b)
+	case *ast.IncDecStmt:
+		op := token.ADD
+		if v.Tok == token.DEC {
+			op = token.SUB
+		}
+		err = expr.execOpAssign(v.X, op, MakeUntypedConst(constant.MakeInt64(1)), v, b)
+	case *ast.IfStmt:
+		return expr.execIf(v, b.child())
+	case *ast.ForStmt:
+		return expr.execFor(v, b.child())
+	case *ast.RangeStmt:
+		return expr.execRange(v, b)
+	case *ast.BranchStmt:
+		if v.Tok == token.BREAK {
+			return ctlBreak, nil, nil
+		}
+		return ctlContinue, nil, nil
+	case *ast.ReturnStmt:
+		r, err = expr.astExpr(v.Results[0], b.args)
+		return ctlReturn, r, err
+	default:
+		err = scriptUnsupportedStmtError().pos(s)
+	}
+	return
+}
+
+// execAssign executes assignment or short variable declaration.
+func (expr *Expression) execAssign(s *ast.AssignStmt, b *scriptBlock) *posError {
+	if s.Tok != token.ASSIGN && s.Tok != token.DEFINE {
+		y, err := expr.astExprAsData(s.Rhs[0], b.args)
+		if err != nil {
+			return err
+		}
+		return expr.execOpAssign(s.Lhs[0], s.Tok-token.ADD_ASSIGN+token.ADD, y, s, b)
+	}
+
+	// Locations are determined before evaluating right hand side (as Go does), but variables are declared after it.
+	var locs []scriptLoc
+	if s.Tok == token.ASSIGN {
+		locs = make([]scriptLoc, len(s.Lhs))
+		for i := range s.Lhs {
+			var err *posError
+			if locs[i], err = expr.scriptLoc(s.Lhs[i], b.args); err != nil {
+				return err
+			}
+		}
+	}
+
+	values, err := expr.scriptValues(s, b.args)
+	if err != nil {
+		return err
+	}
+
+	if s.Tok == token.DEFINE {
+		return expr.execDefine(s, values, b)
+	}
+	for i := range locs {
+		if intErr := locs[i].set(values[i]); intErr != nil {
+			return intErr.pos(s.Lhs[i])
+		}
+	}
+	return nil
+}
+
+// scriptValues evaluates right hand side of assignment.
+// Regular values are copied, so "a, b = b, a" works as expected.
+func (expr *Expression) scriptValues(s *ast.AssignStmt, args Args) (r []Data, err *posError) {
+	switch {
+	case len(s.Lhs) == len(s.Rhs):
+		r = make([]Data, len(s.Rhs))
+		for i := range s.Rhs {
+			if r[i], err = expr.astExprAsData(s.Rhs[i], args); err != nil {
+				return
+			}
+		}
+	case len(s.Rhs) != 1:
+		return nil, scriptAssignCountError(len(s.Lhs), len(s.Rhs)).pos(s)
+	default:
+		rhs := unparen(s.Rhs[0])
+		if len(s.Lhs) == 2 && isCommaOkExpr(rhs) {
+			v, ok, err := expr.astCommaOk(rhs, args)
+			if err != nil {
+				return nil, err
+			}
+			if v.Kind() != Datas {
+				return nil, notExprError(v).pos(rhs)
+			}
+			r = []Data{v.Data(), MakeUntypedBool(ok)}
+			break
+		}
+		vs, err := expr.astMulti(rhs, args)
+		if err != nil {
+			return nil, err
+		}
+		if len(vs) != len(s.Lhs) {
+			return nil, scriptAssignCountError(len(s.Lhs), len(vs)).pos(s)
+		}
+		r = make([]Data, len(vs))
+		for i := range vs {
+			if vs[i].Kind() != Datas {
+				return nil, notExprError(vs[i]).pos(rhs)
+			}
+			r[i] = vs[i].Data()
+		}
+	}
+
+	for i := range r {
+		if r[i].Kind() == Regular {
+			r[i] = MakeRegular(copyValues([]reflect.Value{r[i].Regular()})[0])
+		}
+	}
+	return
+}
+
+// isCommaOkExpr reports whether e may be evaluated in comma-ok form.
+func isCommaOkExpr(e ast.Expr) bool {
+	switch v := e.(type) {
+	case *ast.IndexExpr, *ast.TypeAssertExpr:
+		return true
+	case *ast.UnaryExpr:
+		return v.Op == token.ARROW
+	default:
+		return false
+	}
+}
+
+// execDefine executes short variable declaration with already evaluated values.
+func (expr *Expression) execDefine(s *ast.AssignStmt, values []Data, b *scriptBlock) *posError {
+	newVars := false
+	for i := range s.Lhs {
+		name := s.Lhs[i].(*ast.Ident).Name
+		if name == "_" {
+			if values[i].Kind() == Nil {
+				return scriptUntypedNilError().pos(s.Rhs[0])
+			}
+			continue
+		}
+		if b.decl[name] { // redeclaration
+			if intErr := assign(b.args[name].Data().Regular(), values[i]); intErr != nil {
+				return intErr.pos(s.Lhs[i])
+			}
+			continue
+		}
+		newVars = true
+		t, intErr := scriptVarType(values[i])
+		if intErr != nil {
+			return intErr.pos(s.Lhs[i])
+		}
+		v := reflect.New(t).Elem()
+		if intErr = assign(v, values[i]); intErr != nil {
+			return intErr.pos(s.Lhs[i])
+		}
+		b.declare(name, v)
+	}
+	if !newVars {
+		return scriptNoNewVarsError().pos(s)
+	}
+	return nil
+}
+
+// scriptVarType returns type of variable declared with initial value x.
+func scriptVarType(x Data) (reflect.Type, *intError) {
+	switch x.Kind() {
+	case Regular:
+		return x.Regular().Type(), nil
+	case TypedConst:
+		return x.TypedConst().Type(), nil
+	case UntypedConst:
+		return constanth.DefaultType(x.UntypedConst()), nil
+	case UntypedBool:
+		return reflect.TypeOf(false), nil
+	default:
+		return nil, scriptUntypedNilError()
+	}
+}
+
+// execOpAssign executes operation assignment ("x op= y") and increment & decrement statements.
+func (expr *Expression) execOpAssign(lhs ast.Expr, op token.Token, y Data, s ast.Stmt, b *scriptBlock) *posError {
+	loc, err := expr.scriptLoc(lhs, b.args)
+	if err != nil {
+		return err
+	}
+	if loc.blank() {
+		return scriptBlankValueError().pos(lhs)
+	}
+	x := loc.get()
+
+	var r Data
+	var intErr *intError
+	switch op {
+	case token.SHL, token.SHR:
+		r, intErr = shiftOp(x, op, y)
+	case token.ADD:
+		if intErr = expr.allocateConcat(x, y); intErr == nil {
+			r, intErr = binaryOp(x, op, y)
+		}
+	default:
+		r, intErr = binaryOp(x, op, y)
+	}
+	if intErr == nil {
+		intErr = loc.set(r)
+	}
+	return intErr.pos(s)
+}
+
+// scriptLoc evaluates left hand side of assignment.
+func (expr *Expression) scriptLoc(e ast.Expr, args Args) (r scriptLoc, err *posError) {
+	e = unparen(e)
+	if ident, ok := e.(*ast.Ident); ok && ident.Name == "_" {
+		return
+	}
+
+	if index, ok := e.(*ast.IndexExpr); ok {
+		var x Data
+		if x, err = expr.astExprAsData(index.X, args); err != nil {
+			return
+		}
+		if x.Kind() == Regular && x.Regular().Kind() == reflect.Map {
+			var i Data
+			if i, err = expr.astExprAsData(index.Index, args); err != nil {
+				return
+			}
+			keyT := x.Regular().Type().Key()
+			k, ok := i.Assign(keyT)
+			if !ok {
+				return r, cannotUseAsError(keyT, i, "map index").pos(index.Index)
+			}
+			return scriptLoc{m: x.Regular(), k: k}, nil
+		}
+	}
+
+	x, err := expr.astExprAsData(e, args)
+	if err != nil {
+		return
+	}
+	if x.Kind() != Regular || !x.Regular().CanSet() {
+		return r, assignDstUnsettableError(x).pos(e)
+	}
+	return scriptLoc{v: x.Regular()}, nil
+}
+
+// scriptCond evaluates condition of if & for statements.
+func (expr *Expression) scriptCond(e ast.Expr, args Args) (r bool, err *posError) {
+	x, err := expr.astExprAsData(e, args)
+	if err != nil {
+		return
+	}
+	r, ok := boolVal(x)
+	if !ok {
+		return false, scriptNonBoolCondError(x).pos(e)
+	}
+	return
+}
+
+func (expr *Expression) execIf(s *ast.IfStmt, b *scriptBlock) (ctl scriptCtl, r Value, err *posError) {
+	if s.Init != nil {
+		if _, _, err = expr.execStmt(s.Init, b); err != nil {
+			return
+		}
+	}
+	cond, err := expr.scriptCond(s.Cond, b.args)
+	switch {
+	case err != nil:
+		return
+	case cond:
+		return expr.execStmts(s.Body.List, b.child())
+	case s.Else != nil:
+		return expr.execStmt(s.Else, b)
+	default:
+		return
+	}
+}
+
+func (expr *Expression) execFor(s *ast.ForStmt, b *scriptBlock) (ctl scriptCtl, r Value, err *posError) {
+	if s.Init != nil {
+		if _, _, err = expr.execStmt(s.Init, b); err != nil {
+			return
+		}
+	}
+	for {
+		if err = expr.tick(s); err != nil {
+			return
+		}
+		if s.Cond != nil {
+			var cond bool
+			if cond, err = expr.scriptCond(s.Cond, b.args); err != nil || !cond {
+				return
+			}
+		}
+		ctl, r, err = expr.execStmts(s.Body.List, b.child())
+		switch {
+		case err != nil || ctl == ctlReturn:
+			return
+		case ctl == ctlBreak:
+			return ctlNext, nil, nil
+		}
+		b.renew() // each iteration has its own variables declared in init statement
+		if s.Post != nil {
+			if _, _, err = expr.execStmt(s.Post, b); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// execRange executes for statement with range clause.
+func (expr *Expression) execRange(s *ast.RangeStmt, b *scriptBlock) (ctl scriptCtl, r Value, err *posError) {
+	x, err := expr.astExprAsData(s.X, b.args)
+	if err != nil {
+		return
+	}
+	xR, intErr := scriptRangeRegular(x)
+	if intErr != nil {
+		return ctlNext, nil, intErr.pos(s.X)
+	}
+	xV := xR.Regular()
+
+	// iter executes single iteration with given values of iteration variables.
+	iter := func(k, v reflect.Value) (stop bool) {
+		if err = expr.tick(s); err != nil {
+			return true
+		}
+		it := b.child()
+		for _, iv := range []struct {
+			e ast.Expr
+			v reflect.Value
+		}{{s.Key, k}, {s.Value, v}} {
+			switch {
+			case iv.e == nil:
+			case s.Tok == token.DEFINE:
+				it.declare(iv.e.(*ast.Ident).Name, copyValues([]reflect.Value{iv.v})[0])
+			default:
+				var loc scriptLoc
+				if loc, err = expr.scriptLoc(iv.e, b.args); err != nil {
+					return true
+				}
+				if err = loc.set(MakeRegular(iv.v)).pos(iv.e); err != nil {
+					return true
+				}
+			}
+		}
+		ctl, r, err = expr.execStmts(s.Body.List, it.child())
+		switch {
+		case err != nil || ctl == ctlReturn:
+			return true
+		case ctl == ctlBreak:
+			ctl = ctlNext
+			return true
+		default:
+			ctl = ctlNext
+			return false
+		}
+	}
+
+	switch xV.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if s.Value != nil {
+			return ctlNext, nil, scriptRangeError(x, true).pos(s.Value)
+		}
+		for i := int64(0); i < xV.Int(); i++ {
+			if iter(reflect.ValueOf(i).Convert(xV.Type()), reflect.Value{}) {
+				return
+			}
+		}
+		return
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if s.Value != nil {
+			return ctlNext, nil, scriptRangeError(x, true).pos(s.Value)
+		}
+		for i := uint64(0); i < xV.Uint(); i++ {
+			if iter(reflect.ValueOf(i).Convert(xV.Type()), reflect.Value{}) {
+				return
+			}
+		}
+		return
+	case reflect.String:
+		str := xV.String()
+		for i := 0; i < len(str); {
+			c, size := utf8.DecodeRuneInString(str[i:])
+			if iter(reflect.ValueOf(i), reflect.ValueOf(c)) {
+				return
+			}
+			i += size
+		}
+		return
+	case reflect.Ptr:
+		if xV.Type().Elem().Kind() != reflect.Array {
+			break
+		}
+		if xV.IsNil() && s.Value != nil {
+			return ctlNext, nil, nilDerefError().pos(s.X)
+		}
+		for i := 0; i < xV.Type().Elem().Len(); i++ {
+			var v reflect.Value
+			if s.Value != nil {
+				v = xV.Elem().Index(i)
+			}
+			if iter(reflect.ValueOf(i), v) {
+				return
+			}
+		}
+		return
+	case reflect.Array, reflect.Slice:
+		if xV.Kind() == reflect.Array && s.Value != nil {
+			xV = copyValues([]reflect.Value{xV})[0] // range expression is evaluated once, so array is copied
+		}
+		for i, l := 0, xV.Len(); i < l; i++ {
+			var v reflect.Value
+			if s.Value != nil {
+				v = xV.Index(i)
+			}
+			if iter(reflect.ValueOf(i), v) {
+				return
+			}
+		}
+		return
+	case reflect.Map:
+		for mi := xV.MapRange(); mi.Next(); {
+			if iter(mi.Key(), mi.Value()) {
+				return
+			}
+		}
+		return
+	case reflect.Chan:
+		if xV.Type().ChanDir()&reflect.RecvDir == 0 {
+			break
+		}
+		if s.Value != nil {
+			return ctlNext, nil, scriptRangeError(x, true).pos(s.Value)
+		}
+		for {
+			v, ok, intErr := expr.recv(xV)
+			if intErr != nil {
+				return ctlNext, nil, intErr.pos(s.X)
+			}
+			if !ok || iter(v, reflect.Value{}) {
+				return
+			}
+		}
+	}
+	return ctlNext, nil, scriptRangeError(x, false).pos(s.X)
+}
+
+// scriptRangeRegular converts constant range expression to regular value.
+func scriptRangeRegular(x Data) (Data, *intError) {
+	var t reflect.Type
+	switch x.Kind() {
+	case Regular:
+		return x, nil
+	case TypedConst:
+		t = x.TypedConst().Type()
+	case UntypedConst:
+		t = constanth.DefaultType(x.UntypedConst())
+	default:
+		return nil, scriptRangeError(x, false)
+	}
+	v, ok := x.Assign(t)
+	if !ok {
+		return nil, scriptRangeError(x, false)
+	}
+	return MakeRegular(v), nil
+}
@@ -0,0 +1,256 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/ioutil"
+)
+
+// Script is a list of statements which computes single value.
+// Script supports following statements:
+//  1. short variable declarations ("x, y := 1, 2"),
+//  2. assignments ("x = 1", "s[i], m[k] = 1, 2", "p.F = 1", "*p = 1") including operation assignments ("x += 1") and increments & decrements ("i++"),
+//  3. if & else,
+//  4. for (with condition, with init & post statements and range over arrays, pointers to arrays, slices, strings, maps, channels and integers),
+//  5. break & continue (without labels),
+//  6. expression statements (function calls and receive operations),
+//  7. blocks,
+//  8. return with single expression.
+//
+// Script must end with return statement.
+// Each expression in script is evaluated in the same way as Expression evaluates it.
+// Variables declared in script has the same types as they have in Go (untyped constants take default type).
+type Script struct {
+	expr Expression // used for evaluation of expressions in script
+	body *ast.BlockStmt
+}
+
+// ParseScript parses script from source src.
+// src may be of type string, []byte or io.Reader.
+// filename is used only for positions in errors.
+// pkgPath is fully qualified package name, for more details see package level documentation.
+func ParseScript(filename string, src interface{}, pkgPath string) (r *Script, err error) {
+	text, err := readSource(src)
+	if err != nil {
+		return nil, err
+	}
+
+	// Script is parsed as body of function, line directive keeps positions relative to script source.
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", "package p\nfunc _() {\n//line "+filename+":1:1\n"+text+"\n}\n", 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Decls) != 1 {
+		return nil, scriptInvError().pos(f.Decls[1]).error(fset)
+	}
+
+	r = &Script{expr: Expression{fset: fset, pkgPath: pkgPath}, body: f.Decls[0].(*ast.FuncDecl).Body}
+	if posErr := checkScriptStmts(r.body.List, false); posErr != nil {
+		return nil, posErr.error(fset)
+	}
+	if !isTerminatingList(r.body.List) {
+		return nil, scriptMissingReturnError().pos(&ast.BadStmt{From: r.body.Rbrace, To: r.body.Rbrace}).error(fset)
+	}
+	return
+}
+
+// ParseScriptString parses script from string src.
+// pkgPath is fully qualified package name, for more details see package level documentation.
+func ParseScriptString(src string, pkgPath string) (r *Script, err error) {
+	return ParseScript(DefaultFileName, src, pkgPath)
+}
+
+func readSource(src interface{}) (string, error) {
+	switch s := src.(type) {
+	case string:
+		return s, nil
+	case []byte:
+		return string(s), nil
+	case io.Reader:
+		b, err := ioutil.ReadAll(s)
+		return string(b), err
+	default:
+		return "", errors.New("invalid source")
+	}
+}
+
+// WithOptions returns copy of script which is evaluated with given options.
+func (s *Script) WithOptions(opts EvalOptions) *Script {
+	r := *s
+	r.expr.opts = opts
+	return &r
+}
+
+// Eval executes script with given arguments args and returns value of executed return statement.
+// Variables declared in script and assignments to variables passed via args do not modify args itself.
+// But values reachable via variables passed via args (for example, elements of slices and values pointed by pointers) may be modified by script.
+func (s *Script) Eval(args Args) (r Value, err error) {
+	defer func() {
+		rec := recover()
+		if rec != nil {
+			err = recoveredError(rec)
+		}
+	}()
+
+	scope := make(Args, len(args))
+	for ident, arg := range args {
+		scope[ident] = arg
+	}
+
+	err = scope.validate()
+	if err != nil {
+		return
+	}
+
+	scope.makeCopies() // assignments must not modify variables passed via args even if they are addressable
+
+	err = scope.normalize()
+	if err != nil {
+		return
+	}
+
+	var posErr *posError
+	r, posErr = s.expr.begin().execScript(s.body, scope)
+	err = posErr.error(s.expr.fset)
+	return
+}
+
+// EvalContext executes script like Eval does, but respects ctx (see Expression.EvalContext).
+func (s *Script) EvalContext(ctx context.Context, args Args) (r Value, err error) {
+	s1 := *s
+	s1.expr = *s.expr.withContext(ctx)
+	return s1.Eval(args)
+}
+
+// checkScriptStmts checks what script consists only of supported statements.
+// inLoop true if list is inside for statement.
+func checkScriptStmts(list []ast.Stmt, inLoop bool) *posError {
+	for _, s := range list {
+		if err := checkScriptStmt(s, inLoop); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkScriptStmt(s ast.Stmt, inLoop bool) *posError {
+	switch v := s.(type) {
+	case nil, *ast.EmptyStmt, *ast.IncDecStmt:
+		return nil
+	case *ast.BlockStmt:
+		return checkScriptStmts(v.List, inLoop)
+	case *ast.ExprStmt:
+		switch x := unparen(v.X).(type) {
+		case *ast.CallExpr:
+			return nil
+		case *ast.UnaryExpr:
+			if x.Op == token.ARROW {
+				return nil
+			}
+		}
+		return scriptExprNotUsedError().pos(v)
+	case *ast.AssignStmt:
+		switch v.Tok {
+		case token.DEFINE:
+			for _, l := range v.Lhs {
+				if _, ok := l.(*ast.Ident); !ok {
+					return scriptDefineNonNameError().pos(l)
+				}
+			}
+		case token.ASSIGN:
+		default:
+			if len(v.Lhs) != 1 || len(v.Rhs) != 1 {
+				return scriptOpAssignCountError(v.Tok).pos(v)
+			}
+		}
+		return nil
+	case *ast.IfStmt:
+		if err := checkScriptStmt(v.Init, inLoop); err != nil {
+			return err
+		}
+		if err := checkScriptStmts(v.Body.List, inLoop); err != nil {
+			return err
+		}
+		return checkScriptStmt(v.Else, inLoop)
+	case *ast.ForStmt:
+		if err := checkScriptStmt(v.Init, inLoop); err != nil {
+			return err
+		}
+		if err := checkScriptStmt(v.Post, inLoop); err != nil {
+			return err
+		}
+		return checkScriptStmts(v.Body.List, true)
+	case *ast.RangeStmt:
+		if v.Tok == token.DEFINE {
+			for _, l := range []ast.Expr{v.Key, v.Value} {
+				if _, ok := l.(*ast.Ident); l != nil && !ok {
+					return scriptDefineNonNameError().pos(l)
+				}
+			}
+		}
+		return checkScriptStmts(v.Body.List, true)
+	case *ast.BranchStmt:
+		switch {
+		case v.Label != nil:
+			return scriptLabelError().pos(v)
+		case v.Tok != token.BREAK && v.Tok != token.CONTINUE:
+			return scriptUnsupportedStmtError().pos(v)
+		case !inLoop:
+			return scriptBranchNotInLoopError(v.Tok).pos(v)
+		}
+		return nil
+	case *ast.ReturnStmt:
+		if len(v.Results) != 1 {
+			return scriptReturnCountError(len(v.Results)).pos(v)
+		}
+		return nil
+	default:
+		return scriptUnsupportedStmtError().pos(s)
+	}
+}
+
+// isTerminatingList reports whether list of statements ends with terminating statement (in terms of Go specification).
+func isTerminatingList(list []ast.Stmt) bool {
+	return len(list) > 0 && isTerminating(list[len(list)-1])
+}
+
+func isTerminating(s ast.Stmt) bool {
+	switch v := s.(type) {
+	case *ast.ReturnStmt:
+		return true
+	case *ast.BlockStmt:
+		return isTerminatingList(v.List)
+	case *ast.IfStmt:
+		return v.Else != nil && isTerminatingList(v.Body.List) && isTerminating(v.Else)
+	case *ast.ForStmt:
+		return v.Cond == nil && !hasBreak(v.Body.List)
+	default:
+		return false
+	}
+}
+
+// hasBreak reports whether list contains break statement which refers to enclosing loop.
+func hasBreak(list []ast.Stmt) bool {
+	for _, s := range list {
+		switch v := s.(type) {
+		case *ast.BranchStmt:
+			if v.Tok == token.BREAK {
+				return true
+			}
+		case *ast.BlockStmt:
+			if hasBreak(v.List) {
+				return true
+			}
+		case *ast.IfStmt:
+			if hasBreak(v.Body.List) || v.Else != nil && hasBreak([]ast.Stmt{v.Else}) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"go/constant"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestScript_Eval(t *testing.T) {
+	type testElement struct {
+		src  string
+		args Args
+		r    string // DeepString of result
+		err  string // error message (without position) if any
+	}
+
+	ch := make(chan int, 3)
+	ch <- 1
+	ch <- 2
+	ch <- 3
+	close(ch)
+
+	tests := []testElement{
+		{"return 1 + 2", nil, "3 (type untyped constant)", ""},
+		{"x := 1\nx += 2\nx++\nreturn x", nil, "4 (type int)", ""},
+		{"a, b := 1, 2\na, b = b, a\nreturn a*10 + b", nil, "21 (type int)", ""},
+		{"s := 0\nfor i := 0; i < 5; i++ {\n\tif i == 3 {\n\t\tcontinue\n\t}\n\ts += i\n}\nreturn s", nil, "7 (type int)", ""},
+		{"s := 0\nfor {\n\ts++\n\tif s > 9 {\n\t\tbreak\n\t}\n}\nreturn s", nil, "10 (type int)", ""},
+		{"s := \"\"\nfor i, v := range x {\n\ts += strconv.Itoa(i) + v\n}\nreturn s", Args{"x": MakeDataRegularInterface([]string{"a", "b"}), "strconv.Itoa": MakeDataRegularInterface(strconv.Itoa)}, "0a1b (type string)", ""},
+		{"s := 0\nfor k, v := range m {\n\ts += k * v\n}\nreturn s", Args{"m": MakeDataRegularInterface(map[int]int{1: 2, 3: 4})}, "14 (type int)", ""},
+		{"s := 0\nfor i := range 4 {\n\ts += i\n}\nreturn s", nil, "6 (type int)", ""},
+		{"var8 := int8(0)\nfor range int8(3) {\n\tvar8 += 2\n}\nreturn var8", nil, "6 (type int8)", ""},
+		{"n := 0\nfor _, r := range \"aж\" {\n\tn += int(r)\n}\nreturn n", nil, "1175 (type int)", ""},
+		{"s := 0\nfor v := range ch {\n\ts += v\n}\nreturn s", Args{"ch": MakeDataRegularInterface(ch)}, "6 (type int)", ""},
+		{"m[\"a\"] += 1\nm[\"b\"] = 5\nreturn m[\"a\"] + m[\"b\"]", Args{"m": MakeDataRegularInterface(map[string]int{"a": 1})}, "7 (type int)", ""},
+		{"v, ok := m[\"c\"]\nif !ok {\n\treturn -1\n} else if v > 0 {\n\treturn 1\n}\nreturn 0", Args{"m": MakeDataRegularInterface(map[string]int{})}, "-1 (type untyped constant)", ""},
+		{"if x := 2; x > 1 {\n\tx := \"shadow\"\n\treturn x\n}\nreturn \"\"", nil, "shadow (type string)", ""},
+		{"fs := []func() int{}\nfor i := 0; i < 3; i++ {\n\tfs = append(fs, func() int { return i })\n}\nreturn fs[0]() + fs[2]()", nil, "2 (type int)", ""},
+		{"s[1] = 10\nreturn s[1]", Args{"s": MakeDataRegularInterface([]int{1, 2})}, "10 (type int)", ""},
+		{"b := x\nb = !b\nreturn b", Args{"x": MakeDataUntypedBool(true)}, "false (type bool)", ""},
+		// Errors
+		{"x := 1\nx := 2\nreturn x", nil, "", "no new variables on left side of :="},
+		{"x := nil\nreturn x", nil, "", "use of untyped nil in assignment"},
+		{"x = 1\nreturn x", Args{"x": MakeDataUntypedConst(constant.MakeInt64(1))}, "", "cannot change 1 (type untyped constant) in assignment"},
+		{"var m map[string]int\nreturn 1", nil, "", "statement is not supported in script"},
+		{"m[\"a\"] = 1\nreturn 1", Args{"m": MakeDataRegularInterface(map[string]int(nil))}, "", "assignment to entry in nil map"},
+		{"if 1 {\n\treturn 1\n}\nreturn 0", nil, "", "non-bool 1 (type untyped constant) used as condition"},
+		{"for i, v := range 3 {\n}\nreturn 0", nil, "", "range over 3 (type untyped constant) permits only one iteration variable"},
+		{"for range 1.5 {\n}\nreturn 0", nil, "", "cannot range over 3/2 (type untyped constant)"},
+		{"x := 1\nx", nil, "", "expression evaluated but not used"},
+		{"break\nreturn 1", nil, "", "break is not in a loop"},
+		{"x := 1", nil, "", "missing return"},
+		{"for {\n\tbreak\n}", nil, "", "missing return"},
+		{"a, b := 1\nreturn a", nil, "", "assignment mismatch: 2 variables but 1 value"},
+	}
+
+	for _, test := range tests {
+		s, err := ParseScriptString(test.src, "")
+		if err == nil {
+			var r Value
+			r, err = s.Eval(test.args)
+			if err == nil {
+				if test.err != "" {
+					t.Errorf("%q: expect error %q, got %v", test.src, test.err, r)
+				} else if r.Kind() != Datas || r.Data().DeepString() != test.r {
+					t.Errorf("%q: expect %v, got %v", test.src, test.r, r)
+				}
+				continue
+			}
+		}
+		if test.err == "" || !strings.HasSuffix(err.Error(), ": "+test.err) {
+			t.Errorf("%q: expect error %q, got %v", test.src, test.err, err)
+		}
+	}
+}
+
+func TestScript_EvalArgsNotModified(t *testing.T) {
+	v, w := 1, 2
+	s, err := ParseScriptString("x = 5\npkg.Y = 6\nreturn x + pkg.Y", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := ArgsFromRegulars(ArgsR{"x": reflect.ValueOf(&v).Elem(), "pkg.Y": reflect.ValueOf(&w).Elem()})
+	if r, err := s.Eval(args); err != nil || r.Data().Regular().Interface() != 11 {
+		t.Errorf("expect 11, got %v %v", r, err)
+	}
+	if v != 1 || w != 2 {
+		t.Errorf("assignments must not modify variables passed via args, got %v %v", v, w)
+	}
+}
+
+func TestScript_Positions(t *testing.T) {
+	s, err := ParseScript("script", "x := 0\nx = s[5]\nreturn x", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.Eval(Args{"s": MakeDataRegularInterface([]int{1})})
+	if evalErr, ok := err.(Error); !ok || evalErr.Pos.Filename != "script" || evalErr.Pos.Line != 2 || evalErr.Pos.Column != 5 {
+		t.Errorf("expect error at script:2:5, got %v", err)
+	}
+}
+
+func TestScript_Limits(t *testing.T) {
+	s, err := ParseScriptString("for {\n}", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.WithOptions(EvalOptions{MaxNodes: 100}).Eval(nil)
+	var limitErr NodeLimitError
+	if !errors.As(err, &limitErr) {
+		t.Errorf("expect node limit error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = s.EvalContext(ctx, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expect context error, got %v", err)
+	}
+}
@@ -0,0 +1,365 @@
+package eval
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Simplify returns new expression in which all subexpressions which values are known are replaced with literals.
+// Value of subexpression is known if it is constant or if all identifiers used in it are passed via args (so args describes known values, all other identifiers stay as is).
+// Regular values of boolean, numeric and string types are replaced with conversion of literal to theirs types ("int(5)"), constants are replaced with literals ("7", "int8(7)").
+// Type of such value must be built-in or passed via args, otherwise subexpression is not replaced.
+// Simplify does not call functions (except built-in ones) and does not receive from channels, so calls and receive operations are kept in resulting expression (but theirs arguments are simplified).
+// Operands which must be addressable ("&x", "x.Method") are never replaced with non constant literals.
+// Simplify returns error if known subexpression can not be evaluated.
+// Known subexpressions are evaluated with options of expression (see WithOptions), limits are applied to Simplify call as a whole.
+// Resulting expression has the same package path and options as original one, positions of replaced nodes are kept.
+func (e *Expression) Simplify(args Args) (r *Expression, err error) {
+	defer func() {
+		rec := recover()
+		if rec != nil {
+			r = nil
+			err = recoveredError(rec)
+		}
+	}()
+
+	scope := make(Args, len(args))
+	for ident, arg := range args {
+		scope[ident] = arg
+	}
+
+	err = scope.validate()
+	if err != nil {
+		return
+	}
+
+	scope.makeAddressable()
+
+	err = scope.normalize()
+	if err != nil {
+		return
+	}
+
+	expr := Expression{fset: e.fset, pkgPath: e.pkgPath, opts: e.opts, ctx: e.ctx}
+	s, posErr := expr.begin().simplify(e.e, scope, nil, false)
+	if posErr != nil {
+		return nil, posErr.error(e.fset)
+	}
+	r = &Expression{e: s, fset: e.fset, pkgPath: e.pkgPath, opts: e.opts}
+	return
+}
+
+var (
+	astExprType      = reflect.TypeOf((*ast.Expr)(nil)).Elem()
+	astExprSliceType = reflect.TypeOf([]ast.Expr(nil))
+)
+
+// simplify returns simplified copy of e (not modified nodes are shared with e).
+// bound contains names of parameters of enclosing function literals.
+// addressable is true if e must stay addressable.
+func (expr *Expression) simplify(e ast.Expr, args Args, bound map[string]bool, addressable bool) (r ast.Expr, err *posError) {
+	if e == nil {
+		return nil, nil
+	}
+
+	if expr.isKnown(e, args, bound) {
+		var v Value
+		if v, err = expr.astExpr(e, args); err != nil {
+			return
+		}
+		if lit, ok := literal(v, args, e.Pos(), addressable); ok {
+			return lit, nil
+		}
+	}
+
+	switch v := e.(type) {
+	case *ast.FuncLit:
+		if v.Body == nil || len(v.Body.List) != 1 {
+			return e, nil
+		}
+		ret, ok := v.Body.List[0].(*ast.ReturnStmt)
+		if !ok || len(ret.Results) != 1 {
+			return e, nil
+		}
+		inner := make(map[string]bool, len(bound))
+		for name := range bound {
+			inner[name] = true
+		}
+		for _, fields := range []*ast.FieldList{v.Type.Params, v.Type.Results} {
+			if fields == nil {
+				continue
+			}
+			for _, field := range fields.List {
+				for _, name := range field.Names {
+					inner[name.Name] = true
+				}
+			}
+		}
+		res, err := expr.simplify(ret.Results[0], args, inner, false)
+		if err != nil {
+			return nil, err
+		}
+		ret1, body, lit := *ret, *v.Body, *v
+		ret1.Results = []ast.Expr{res}
+		body.List = []ast.Stmt{&ret1}
+		lit.Body = &body
+		return &lit, nil
+	case *ast.CompositeLit:
+		lit := *v
+		if lit.Type, err = expr.simplify(v.Type, args, bound, false); err != nil {
+			return
+		}
+		keys := compositeLitKeys(v.Type)
+		lit.Elts = make([]ast.Expr, len(v.Elts))
+		for i, elt := range v.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				if lit.Elts[i], err = expr.simplify(elt, args, bound, false); err != nil {
+					return
+				}
+				continue
+			}
+			kv1 := *kv
+			if isLitKeyExpr(kv.Key, keys) {
+				if kv1.Key, err = expr.simplify(kv.Key, args, bound, false); err != nil {
+					return
+				}
+			}
+			if kv1.Value, err = expr.simplify(kv.Value, args, bound, false); err != nil {
+				return
+			}
+			lit.Elts[i] = &kv1
+		}
+		return &lit, nil
+	}
+
+	// Copy node and simplify all its subexpressions
+	rV := reflect.New(reflect.TypeOf(e).Elem())
+	rV.Elem().Set(reflect.ValueOf(e).Elem())
+	for i := 0; i < rV.Elem().NumField(); i++ {
+		field := rV.Elem().Field(i)
+		switch field.Type() {
+		case astExprType:
+			if field.IsNil() {
+				continue
+			}
+			s, err := expr.simplify(field.Interface().(ast.Expr), args, bound, operandAddressable(e, rV.Elem().Type().Field(i).Name, addressable))
+			if err != nil {
+				return nil, err
+			}
+			field.Set(reflect.ValueOf(&s).Elem())
+		case astExprSliceType:
+			if field.Len() == 0 {
+				continue
+			}
+			list := make([]ast.Expr, field.Len())
+			for j := range list {
+				if list[j], err = expr.simplify(field.Index(j).Interface().(ast.Expr), args, bound, false); err != nil {
+					return
+				}
+			}
+			field.Set(reflect.ValueOf(list))
+		}
+	}
+	return rV.Interface().(ast.Expr), nil
+}
+
+// operandAddressable reports whether operand (field of node e) must be addressable.
+func operandAddressable(e ast.Expr, field string, addressable bool) bool {
+	if field != "X" {
+		return false
+	}
+	switch v := e.(type) {
+	case *ast.UnaryExpr:
+		return v.Op == token.AND
+	case *ast.SelectorExpr, *ast.SliceExpr:
+		return true
+	case *ast.IndexExpr, *ast.ParenExpr:
+		return addressable
+	default:
+		return false
+	}
+}
+
+// isKnown reports whether value of e may be computed using only args, without calling functions and receiving from channels.
+func (expr *Expression) isKnown(e ast.Expr, args Args, bound map[string]bool) bool {
+	if lit, ok := e.(*ast.CompositeLit); ok && lit.Type == nil {
+		return false // literal with elided type can not be evaluated apart from enclosing literal
+	}
+
+	f := freeIdents{expr: expr, index: make(map[string]int)}
+	f.walk(e, nil)
+	for _, ident := range f.r {
+		if bound[ident.Base()] {
+			return false
+		}
+		if _, ok := freeIdentArg(args, ident); !ok {
+			return false
+		}
+	}
+
+	known := true
+	ast.Inspect(e, func(n ast.Node) bool {
+		if !known {
+			return false // walk does not stop at once, so siblings of unknown node must not reset known
+		}
+		switch v := n.(type) {
+		case *ast.FuncLit:
+			known = false
+		case *ast.UnaryExpr:
+			known = v.Op != token.ARROW
+		case *ast.CallExpr:
+			// Only conversions and calls of built-in functions are allowed
+			f, err := expr.staticValue(v.Fun, args)
+			known = err == nil && (f.Kind() == Type || f.Kind() == BuiltInFunc)
+		}
+		return known
+	})
+	return known
+}
+
+// staticValue computes e like Compile does: without calling functions, receiving from channels and so on.
+func (expr *Expression) staticValue(e ast.Expr, args Args) (r Value, err *posError) {
+	check := *expr
+	check.check = &checkState{}
+	return check.astExpr(e, args)
+}
+
+// literal returns AST of literal representing x positioned at pos.
+// ok is false if x can not be represented by literal.
+// If addressable is true then only constants are represented.
+func literal(x Value, args Args, pos token.Pos, addressable bool) (r ast.Expr, ok bool) {
+	if x.Kind() != Datas {
+		return nil, false
+	}
+	d := x.Data()
+	switch d.Kind() {
+	case Nil:
+		return &ast.Ident{NamePos: pos, Name: "nil"}, true
+	case UntypedBool:
+		return &ast.Ident{NamePos: pos, Name: strconv.FormatBool(d.UntypedBool())}, true
+	case UntypedConst:
+		return constLiteral(d.UntypedConst(), pos)
+	case TypedConst:
+		return conversionLiteral(d.TypedConst().Type(), d.TypedConst().Untyped(), args, pos)
+	case Regular:
+		if addressable {
+			return nil, false
+		}
+		v := d.Regular()
+		var c constant.Value
+		switch v.Kind() {
+		case reflect.Bool:
+			c = constant.MakeBool(v.Bool())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			c = constant.MakeInt64(v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			c = constant.MakeUint64(v.Uint())
+		case reflect.Float32, reflect.Float64:
+			c = constant.MakeFloat64(v.Float())
+		case reflect.Complex64, reflect.Complex128:
+			c = constant.BinaryOp(constant.MakeFloat64(real(v.Complex())), token.ADD, constant.MakeImag(constant.MakeFloat64(imag(v.Complex()))))
+		case reflect.String:
+			c = constant.MakeString(v.String())
+		default:
+			return nil, false
+		}
+		return conversionLiteral(v.Type(), c, args, pos)
+	default:
+		return nil, false
+	}
+}
+
+// conversionLiteral returns AST of conversion of constant c to type t ("T(c)").
+func conversionLiteral(t reflect.Type, c constant.Value, args Args, pos token.Pos) (r ast.Expr, ok bool) {
+	fun, ok := typeLiteral(t, args, pos)
+	if !ok {
+		return
+	}
+	arg, ok := constLiteral(c, pos)
+	if !ok {
+		return
+	}
+	return &ast.CallExpr{Fun: fun, Lparen: pos, Args: []ast.Expr{arg}, Rparen: pos}, true
+}
+
+// typeLiteral returns AST of name of type t: name of built-in type or identifier of argument which is t.
+func typeLiteral(t reflect.Type, args Args, pos token.Pos) (r ast.Expr, ok bool) {
+	if builtInTypes[t.String()] == t {
+		return &ast.Ident{NamePos: pos, Name: t.String()}, true
+	}
+
+	var names []string
+	for ident, arg := range args {
+		switch arg.Kind() {
+		case Type:
+			if arg.Type() == t {
+				names = append(names, ident)
+			}
+		case Package:
+			for member, v := range arg.Package() {
+				if v.Kind() == Type && v.Type() == t {
+					names = append(names, ident+"."+member)
+				}
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil, false
+	}
+	sort.Strings(names)
+	if i := strings.IndexByte(names[0], '.'); i >= 0 {
+		return &ast.SelectorExpr{X: &ast.Ident{NamePos: pos, Name: names[0][:i]}, Sel: &ast.Ident{NamePos: pos, Name: names[0][i+1:]}}, true
+	}
+	return &ast.Ident{NamePos: pos, Name: names[0]}, true
+}
+
+// constLiteral returns AST of untyped constant expression which value is c.
+func constLiteral(c constant.Value, pos token.Pos) (r ast.Expr, ok bool) {
+	switch c.Kind() {
+	case constant.Bool:
+		return &ast.Ident{NamePos: pos, Name: strconv.FormatBool(constant.BoolVal(c))}, true
+	case constant.String:
+		return &ast.BasicLit{ValuePos: pos, Kind: token.STRING, Value: strconv.Quote(constant.StringVal(c))}, true
+	case constant.Int, constant.Float:
+		if constant.Sign(c) < 0 {
+			x, ok := constLiteral(constant.UnaryOp(token.SUB, c, 0), pos)
+			return &ast.UnaryExpr{OpPos: pos, Op: token.SUB, X: x}, ok
+		}
+		if c.Kind() == constant.Int {
+			return &ast.BasicLit{ValuePos: pos, Kind: token.INT, Value: c.ExactString()}, true
+		}
+		// Shortest float literal if it is exact, otherwise exact fraction.
+		if f, _ := constant.Float64Val(c); !math.IsInf(f, 0) {
+			s := strconv.FormatFloat(f, 'g', -1, 64)
+			if !strings.ContainsAny(s, ".e") {
+				s += ".0"
+			}
+			if constant.Compare(constant.MakeFromLiteral(s, token.FLOAT, 0), token.EQL, c) {
+				return &ast.BasicLit{ValuePos: pos, Kind: token.FLOAT, Value: s}, true
+			}
+		}
+		num := &ast.BasicLit{ValuePos: pos, Kind: token.FLOAT, Value: constant.Num(c).ExactString() + ".0"}
+		denom := &ast.BasicLit{ValuePos: pos, Kind: token.INT, Value: constant.Denom(c).ExactString()}
+		return &ast.ParenExpr{Lparen: pos, X: &ast.BinaryExpr{X: num, OpPos: pos, Op: token.QUO, Y: denom}, Rparen: pos}, true
+	case constant.Complex:
+		re, ok := constLiteral(constant.Real(c), pos)
+		if !ok {
+			return nil, false
+		}
+		im, ok := constLiteral(constant.Imag(c), pos)
+		if !ok {
+			return nil, false
+		}
+		imag := &ast.BinaryExpr{X: im, OpPos: pos, Op: token.MUL, Y: &ast.BasicLit{ValuePos: pos, Kind: token.IMAG, Value: "1i"}}
+		return &ast.ParenExpr{Lparen: pos, X: &ast.BinaryExpr{X: re, OpPos: pos, Op: token.ADD, Y: imag}, Rparen: pos}, true
+	default:
+		return nil, false
+	}
+}
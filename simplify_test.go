@@ -0,0 +1,121 @@
+package eval
+
+import (
+	"bytes"
+	"errors"
+	"go/constant"
+	"go/printer"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+func TestExpression_Simplify(t *testing.T) {
+	type myInt int
+	type testElement struct {
+		expr string
+		args Args
+		r    string
+	}
+
+	known := Args{
+		"a":      MakeDataRegularInterface(5),
+		"s":      MakeDataRegularInterface([]int{1, 2}),
+		"k":      MakeDataUntypedConst(constant.MakeInt64(10)),
+		"m":      MakeDataRegularInterface(myInt(3)),
+		"MyInt":  MakeType(reflect.TypeOf(myInt(0))),
+		"f":      MakeDataRegularInterface(func(x int) int { panic("must not be called") }),
+		"pkg.Pi": MakeDataUntypedConst(constant.MakeFloat64(3.5)),
+		"K":      MakeDataUntypedConst(constant.MakeInt64(1)),
+		"Point":  MakeType(reflect.TypeOf(struct{ K int }{})),
+	}
+
+	tests := []testElement{
+		{"1 + 2*3", nil, "7"},
+		{"x + 2*3", nil, "x + 6"},
+		{"x + 1.0/3", nil, "x + (1.0 / 3)"},
+		{"-(1 << 2) + x", nil, "-4 + x"},
+		{"int8(1) + int8(2)", nil, "int8(3)"},
+		{`"a" + "b" == x`, nil, `"ab" == x`},
+		{"1 < 2 && x", nil, "true && x"},
+		{"(2 + 3i) * x", nil, "(2 + 3.0*1i) * x"},
+		{"a*k + x", known, "int(50) + x"},
+		{"len(s) + s[1] + x", known, "int(4) + x"},
+		{"m + 1", known, "MyInt(4)"},
+		{"f(a + 1) + x", known, "f(int(6)) + x"},
+		{"&a", known, "&a"},
+		{"func(a int) float64 { return k*pkg.Pi + float64(a) }", known, "func(a int) float64 {\n\treturn 35.0 + float64(a)\n}"},
+		{"struct{ K int }{K: k}.K", known, "int(10)"},
+		{"[]int{k: 1}", known, "[]int{10: 1}"},
+		{"Point{K: a + x}", known, "Point{K: int(5) + x}"}, // field name K must not be replaced with argument K
+		{"[]map[int]int{{k + 1: a}, {x: a}}", known, "[]map[int]int{{11: int(5)}, {x: int(5)}}"},
+	}
+
+	for _, test := range tests {
+		e, err := ParseString(test.expr, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := e.Simplify(test.args)
+		if err != nil {
+			t.Errorf("%v: %v", test.expr, err)
+			continue
+		}
+		var buf bytes.Buffer
+		if err = printer.Fprint(&buf, token.NewFileSet(), r.e); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != test.r {
+			t.Errorf("%v: expect %v, got %v", test.expr, test.r, buf.String())
+		}
+	}
+
+	// Simplified expression evaluates to the same value
+	e, err := ParseString("a*k + x", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := e.Simplify(known)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := r.EvalToInterface(Args{"x": MakeDataRegularInterface(1)})
+	if err != nil || v != 51 {
+		t.Errorf("expect 51, got %v %v", v, err)
+	}
+
+	// Receive from channel is never performed, even if sibling is known
+	ch := make(chan int, 1)
+	ch <- 40
+	e, err = ParseString("(<-ch) + -x", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err = e.Simplify(Args{"ch": MakeDataRegularInterface(ch), "x": MakeDataRegularInterface(2)})
+	if err != nil || r.String() != "<-ch + int(-2)" || len(ch) != 1 {
+		t.Errorf("expect %v, got %v %v (%v values in channel)", "<-ch + int(-2)", r, err, len(ch))
+	}
+
+	// Errors in known subexpressions are reported
+	e, err = ParseString("x + s[5]", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = e.Simplify(known); err == nil || err.Error() != "expression:1:5: runtime error: index out of range [5] with length 2" {
+		t.Errorf("expect positioned error, got %v", err)
+	}
+
+	// Options are applied to evaluation of known subexpressions
+	e, err = ParseString("x + (a + k*2)", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = e.WithOptions(EvalOptions{MaxNodes: 2}).Simplify(known)
+	var limitErr NodeLimitError
+	if !errors.As(err, &limitErr) || limitErr.Limit != 2 {
+		t.Errorf("expect node limit error, got %v", err)
+	}
+	if r, err = e.WithOptions(EvalOptions{MaxNodes: 10}).Simplify(known); err != nil || r.opts.MaxNodes != 10 {
+		t.Errorf("expect simplified expression with options, got %v %v", r, err)
+	}
+}
@@ -1,14 +1,15 @@
 package eval
 
 import (
+	"github.com/apaxa-go/helper/strconvh"
 	"reflect"
 )
 
-const indexSkipped int = -1
+const indexSkipped int = -int(^uint(0)>>1) - 1 // minimal int, negative indexes of variables are valid until slicing
 
 // Returns int index value for passed Value.
 // i may be nil (if index omitted).
-// Result also checked for not negative value (negative value cause error).
+// Constant result also checked for not negative value (negative value cause error), negative value of variable is reported while slicing (as run-time error).
 func getSliceIndex(i Data) (r int, err *intError) {
 	if i == nil {
 		return indexSkipped, nil
@@ -17,13 +18,23 @@ func getSliceIndex(i Data) (r int, err *intError) {
 	if !ok {
 		return 0, convertUnableError(reflect.TypeOf(int(0)), i)
 	}
-	if r < 0 {
+	if r < 0 && i.IsConst() {
 		return 0, indexOutOfRangeError(r)
 	}
 	return
 }
 
-func slice2(x reflect.Value, low, high int) (r Value, err *intError) {
+// sliceLimit returns upper bound of slice indexes for x and its description as in Go run-time errors.
+func sliceLimit(x reflect.Value) (limit int, what string) {
+	if x.Kind() == reflect.Slice {
+		return x.Cap(), "capacity"
+	}
+	return x.Len(), "length"
+}
+
+// slice2 performs x[low:high].
+// If static is true then bounds are known at compile time and invalid indexes are reported as compile-time errors, otherwise they are reported as Go run-time errors.
+func slice2(x reflect.Value, low, high int, static bool) (r Value, err *intError) {
 	// resolve pointer to array
 	if x.Kind() == reflect.Ptr && x.Elem().Kind() == reflect.Array {
 		x = x.Elem()
@@ -45,19 +56,29 @@ func slice2(x reflect.Value, low, high int) (r Value, err *intError) {
 	}
 
 	// validate indexes
+	limit, what := sliceLimit(x)
 	switch {
-	case low < 0:
+	case static && low < 0:
 		return nil, indexOutOfRangeError(low)
-	case high > x.Len():
+	case static && high > limit:
 		return nil, indexOutOfRangeError(high)
-	case low > high:
+	case static && low > high:
 		return nil, invSliceIndexError(low, high)
+	case high < 0:
+		return nil, sliceBoundsError("[:"+strconvh.FormatInt(high)+"]", "", 0)
+	case high > limit:
+		return nil, sliceBoundsError("[:"+strconvh.FormatInt(high)+"]", what, limit)
+	case low < 0:
+		return nil, sliceBoundsError("["+strconvh.FormatInt(low)+":]", "", 0)
+	case low > high:
+		return nil, sliceBoundsError("["+strconvh.FormatInt(low)+":"+strconvh.FormatInt(high)+"]", "", 0)
 	}
 
 	return MakeDataRegular(x.Slice(low, high)), nil
 }
 
-func slice3(x reflect.Value, low, high, max int) (r Value, err *intError) {
+// slice3 performs x[low:high:max] (see slice2 for static meaning).
+func slice3(x reflect.Value, low, high, max int, static bool) (r Value, err *intError) {
 	// resolve pointer to array
 	if x.Kind() == reflect.Ptr && x.Elem().Kind() == reflect.Array {
 		x = x.Elem()
@@ -79,15 +100,28 @@ func slice3(x reflect.Value, low, high, max int) (r Value, err *intError) {
 	if high == indexSkipped || max == indexSkipped {
 		return nil, invSlice3IndexOmitted()
 	}
+	limit, what := sliceLimit(x)
 	switch {
-	case low < 0:
+	case static && low < 0:
 		return nil, indexOutOfRangeError(low)
-	case max > x.Cap():
-		return nil, indexOutOfRangeError(high)
-	case low > high:
+	case static && max > limit:
+		return nil, indexOutOfRangeError(max)
+	case static && low > high:
 		return nil, invSliceIndexError(low, high)
-	case high > max:
+	case static && high > max:
 		return nil, invSliceIndexError(high, max)
+	case max < 0:
+		return nil, sliceBoundsError("[::"+strconvh.FormatInt(max)+"]", "", 0)
+	case max > limit:
+		return nil, sliceBoundsError("[::"+strconvh.FormatInt(max)+"]", what, limit)
+	case high < 0:
+		return nil, sliceBoundsError("[:"+strconvh.FormatInt(high)+":]", "", 0)
+	case high > max:
+		return nil, sliceBoundsError("[:"+strconvh.FormatInt(high)+":"+strconvh.FormatInt(max)+"]", "", 0)
+	case low < 0:
+		return nil, sliceBoundsError("["+strconvh.FormatInt(low)+"::]", "", 0)
+	case low > high:
+		return nil, sliceBoundsError("["+strconvh.FormatInt(low)+":"+strconvh.FormatInt(high)+":]", "", 0)
 	}
 
 	return MakeDataRegular(x.Slice3(low, high, max)), nil
@@ -0,0 +1,58 @@
+package stdlib
+
+import (
+	"bytes"
+	"github.com/apaxa-go/eval"
+)
+
+// Bytes returns arguments for package bytes.
+func Bytes() eval.Args {
+	return eval.Args{
+		// Constants
+		"bytes.MinRead": untypedInt(bytes.MinRead),
+
+		// Variables
+		"bytes.ErrTooLarge": variable(&bytes.ErrTooLarge),
+
+		// Types
+		"bytes.Buffer": typeOf((*bytes.Buffer)(nil)),
+		"bytes.Reader": typeOf((*bytes.Reader)(nil)),
+
+		// Functions
+		"bytes.Compare":         function(bytes.Compare),
+		"bytes.Contains":        function(bytes.Contains),
+		"bytes.ContainsAny":     function(bytes.ContainsAny),
+		"bytes.ContainsRune":    function(bytes.ContainsRune),
+		"bytes.Count":           function(bytes.Count),
+		"bytes.Cut":             function(bytes.Cut),
+		"bytes.Equal":           function(bytes.Equal),
+		"bytes.EqualFold":       function(bytes.EqualFold),
+		"bytes.Fields":          function(bytes.Fields),
+		"bytes.HasPrefix":       function(bytes.HasPrefix),
+		"bytes.HasSuffix":       function(bytes.HasSuffix),
+		"bytes.Index":           function(bytes.Index),
+		"bytes.IndexAny":        function(bytes.IndexAny),
+		"bytes.IndexByte":       function(bytes.IndexByte),
+		"bytes.IndexRune":       function(bytes.IndexRune),
+		"bytes.Join":            function(bytes.Join),
+		"bytes.LastIndex":       function(bytes.LastIndex),
+		"bytes.LastIndexByte":   function(bytes.LastIndexByte),
+		"bytes.NewBuffer":       function(bytes.NewBuffer),
+		"bytes.NewBufferString": function(bytes.NewBufferString),
+		"bytes.NewReader":       function(bytes.NewReader),
+		"bytes.Repeat":          function(bytes.Repeat),
+		"bytes.Replace":         function(bytes.Replace),
+		"bytes.ReplaceAll":      function(bytes.ReplaceAll),
+		"bytes.Runes":           function(bytes.Runes),
+		"bytes.Split":           function(bytes.Split),
+		"bytes.SplitN":          function(bytes.SplitN),
+		"bytes.ToLower":         function(bytes.ToLower),
+		"bytes.ToUpper":         function(bytes.ToUpper),
+		"bytes.Trim":            function(bytes.Trim),
+		"bytes.TrimLeft":        function(bytes.TrimLeft),
+		"bytes.TrimPrefix":      function(bytes.TrimPrefix),
+		"bytes.TrimRight":       function(bytes.TrimRight),
+		"bytes.TrimSpace":       function(bytes.TrimSpace),
+		"bytes.TrimSuffix":      function(bytes.TrimSuffix),
+	}
+}
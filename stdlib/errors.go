@@ -0,0 +1,17 @@
+package stdlib
+
+import (
+	"errors"
+	"github.com/apaxa-go/eval"
+)
+
+// Errors returns arguments for package errors.
+func Errors() eval.Args {
+	return eval.Args{
+		"errors.As":     function(errors.As),
+		"errors.Is":     function(errors.Is),
+		"errors.Join":   function(errors.Join),
+		"errors.New":    function(errors.New),
+		"errors.Unwrap": function(errors.Unwrap),
+	}
+}
@@ -0,0 +1,21 @@
+package stdlib
+
+import (
+	"fmt"
+	"github.com/apaxa-go/eval"
+)
+
+// Fmt returns arguments for package fmt.
+// Only functions which format to string are included (no printing & scanning).
+func Fmt() eval.Args {
+	return eval.Args{
+		// Types
+		"fmt.Stringer": typeOf((*fmt.Stringer)(nil)),
+
+		// Functions
+		"fmt.Errorf":   function(fmt.Errorf),
+		"fmt.Sprint":   function(fmt.Sprint),
+		"fmt.Sprintf":  function(fmt.Sprintf),
+		"fmt.Sprintln": function(fmt.Sprintln),
+	}
+}
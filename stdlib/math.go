@@ -0,0 +1,108 @@
+package stdlib
+
+import (
+	"github.com/apaxa-go/eval"
+	"go/constant"
+	"go/token"
+	"math"
+)
+
+// Math returns arguments for package math.
+func Math() eval.Args {
+	const (
+		ln2  = "0.693147180559945309417232121458176568075500134360255254120680009"
+		ln10 = "2.30258509299404568401799145468436420760110148862877297603332790"
+	)
+	return eval.Args{
+		// Mathematical constants
+		"math.E":       untypedFloat("2.71828182845904523536028747135266249775724709369995957496696763"),
+		"math.Pi":      untypedFloat("3.14159265358979323846264338327950288419716939937510582097494459"),
+		"math.Phi":     untypedFloat("1.61803398874989484820458683436563811772030917980576286213544862"),
+		"math.Sqrt2":   untypedFloat("1.41421356237309504880168872420969807856967187537694807317667974"),
+		"math.SqrtE":   untypedFloat("1.64872127070012814684865078781416357165377610071014801157507931"),
+		"math.SqrtPi":  untypedFloat("1.77245385090551602729816748334114518279754945612238712821380779"),
+		"math.SqrtPhi": untypedFloat("1.27201964951406896425242246173749149171560804184009624861664038"),
+		"math.Ln2":     untypedFloat(ln2),
+		"math.Log2E":   untypedInverse(ln2),
+		"math.Ln10":    untypedFloat(ln10),
+		"math.Log10E":  untypedInverse(ln10),
+
+		// Floating-point limit values (they are exactly representable by float64)
+		"math.MaxFloat32":             eval.MakeDataUntypedConst(constant.MakeFloat64(math.MaxFloat32)),
+		"math.SmallestNonzeroFloat32": eval.MakeDataUntypedConst(constant.MakeFloat64(math.SmallestNonzeroFloat32)),
+		"math.MaxFloat64":             eval.MakeDataUntypedConst(constant.MakeFloat64(math.MaxFloat64)),
+		"math.SmallestNonzeroFloat64": eval.MakeDataUntypedConst(constant.MakeFloat64(math.SmallestNonzeroFloat64)),
+
+		// Integer limit values
+		"math.MaxInt":    untypedInt(math.MaxInt),
+		"math.MinInt":    untypedInt(math.MinInt),
+		"math.MaxInt8":   untypedInt(math.MaxInt8),
+		"math.MinInt8":   untypedInt(math.MinInt8),
+		"math.MaxInt16":  untypedInt(math.MaxInt16),
+		"math.MinInt16":  untypedInt(math.MinInt16),
+		"math.MaxInt32":  untypedInt(math.MaxInt32),
+		"math.MinInt32":  untypedInt(math.MinInt32),
+		"math.MaxInt64":  untypedInt(math.MaxInt64),
+		"math.MinInt64":  untypedInt(math.MinInt64),
+		"math.MaxUint":   eval.MakeDataUntypedConst(constant.MakeUint64(math.MaxUint)),
+		"math.MaxUint8":  untypedInt(math.MaxUint8),
+		"math.MaxUint16": untypedInt(math.MaxUint16),
+		"math.MaxUint32": untypedInt(math.MaxUint32),
+		"math.MaxUint64": eval.MakeDataUntypedConst(constant.MakeUint64(math.MaxUint64)),
+
+		// Functions
+		"math.Abs":             function(math.Abs),
+		"math.Acos":            function(math.Acos),
+		"math.Acosh":           function(math.Acosh),
+		"math.Asin":            function(math.Asin),
+		"math.Asinh":           function(math.Asinh),
+		"math.Atan":            function(math.Atan),
+		"math.Atan2":           function(math.Atan2),
+		"math.Atanh":           function(math.Atanh),
+		"math.Cbrt":            function(math.Cbrt),
+		"math.Ceil":            function(math.Ceil),
+		"math.Copysign":        function(math.Copysign),
+		"math.Cos":             function(math.Cos),
+		"math.Cosh":            function(math.Cosh),
+		"math.Dim":             function(math.Dim),
+		"math.Exp":             function(math.Exp),
+		"math.Exp2":            function(math.Exp2),
+		"math.Expm1":           function(math.Expm1),
+		"math.Float32bits":     function(math.Float32bits),
+		"math.Float32frombits": function(math.Float32frombits),
+		"math.Float64bits":     function(math.Float64bits),
+		"math.Float64frombits": function(math.Float64frombits),
+		"math.Floor":           function(math.Floor),
+		"math.Hypot":           function(math.Hypot),
+		"math.Inf":             function(math.Inf),
+		"math.IsInf":           function(math.IsInf),
+		"math.IsNaN":           function(math.IsNaN),
+		"math.Log":             function(math.Log),
+		"math.Log10":           function(math.Log10),
+		"math.Log1p":           function(math.Log1p),
+		"math.Log2":            function(math.Log2),
+		"math.Max":             function(math.Max),
+		"math.Min":             function(math.Min),
+		"math.Mod":             function(math.Mod),
+		"math.Modf":            function(math.Modf),
+		"math.NaN":             function(math.NaN),
+		"math.Pow":             function(math.Pow),
+		"math.Pow10":           function(math.Pow10),
+		"math.Remainder":       function(math.Remainder),
+		"math.Round":           function(math.Round),
+		"math.RoundToEven":     function(math.RoundToEven),
+		"math.Signbit":         function(math.Signbit),
+		"math.Sin":             function(math.Sin),
+		"math.Sinh":            function(math.Sinh),
+		"math.Sqrt":            function(math.Sqrt),
+		"math.Tan":             function(math.Tan),
+		"math.Tanh":            function(math.Tanh),
+		"math.Trunc":           function(math.Trunc),
+	}
+}
+
+// untypedInverse returns untyped constant 1/x where x is a float literal.
+func untypedInverse(lit string) eval.Value {
+	x := constant.MakeFromLiteral(lit, token.FLOAT, 0)
+	return eval.MakeDataUntypedConst(constant.BinaryOp(constant.MakeInt64(1), token.QUO, x))
+}
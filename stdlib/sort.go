@@ -0,0 +1,36 @@
+package stdlib
+
+import (
+	"github.com/apaxa-go/eval"
+	"sort"
+)
+
+// Sort returns arguments for package sort.
+func Sort() eval.Args {
+	return eval.Args{
+		// Types
+		"sort.Float64Slice": typeOf((*sort.Float64Slice)(nil)),
+		"sort.IntSlice":     typeOf((*sort.IntSlice)(nil)),
+		"sort.Interface":    typeOf((*sort.Interface)(nil)),
+		"sort.StringSlice":  typeOf((*sort.StringSlice)(nil)),
+
+		// Functions
+		"sort.Float64s":          function(sort.Float64s),
+		"sort.Float64sAreSorted": function(sort.Float64sAreSorted),
+		"sort.Ints":              function(sort.Ints),
+		"sort.IntsAreSorted":     function(sort.IntsAreSorted),
+		"sort.IsSorted":          function(sort.IsSorted),
+		"sort.Reverse":           function(sort.Reverse),
+		"sort.Search":            function(sort.Search),
+		"sort.SearchFloat64s":    function(sort.SearchFloat64s),
+		"sort.SearchInts":        function(sort.SearchInts),
+		"sort.SearchStrings":     function(sort.SearchStrings),
+		"sort.Slice":             function(sort.Slice),
+		"sort.SliceIsSorted":     function(sort.SliceIsSorted),
+		"sort.SliceStable":       function(sort.SliceStable),
+		"sort.Sort":              function(sort.Sort),
+		"sort.Stable":            function(sort.Stable),
+		"sort.Strings":           function(sort.Strings),
+		"sort.StringsAreSorted":  function(sort.StringsAreSorted),
+	}
+}
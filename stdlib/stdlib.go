@@ -0,0 +1,80 @@
+// Package stdlib provides ready to use arguments (eval.Args) with commonly used parts of Go standard library.
+//
+// Each bundle (Math, Strings, ...) returns new eval.Args with identifiers in the "package.Name" notation, so they can be passed to evaluation as is or after merging with other arguments:
+//
+//	args := stdlib.Merge(stdlib.Fmt(), stdlib.Math(), eval.ArgsFromInterfaces(eval.ArgsI{"x": 5}))
+//	r, err := expr.EvalToInterface(args) // expr may be, for example, `fmt.Sprint(math.Sqrt(float64(x)))`
+//
+// Evaluation modifies passed arguments, so each call of bundle function returns new eval.Args.
+//
+// Constants are passed as typed or untyped constants (the same as in Go), variables are passed as addressable regular variables (so expression sees actual value of variable), types are passed via eval.MakeType.
+// Bundles are curated, not full: functions with side effects outside of process (for example, writing to standard output or reading files) are omitted.
+//
+// Bundles contain members added to standard library up to Go 1.20 (errors.Join, time.DateTime, ...), so this package requires Go 1.20 or later.
+package stdlib
+
+import (
+	"github.com/apaxa-go/eval"
+	"github.com/apaxa-go/helper/goh/constanth"
+	"github.com/apaxa-go/helper/reflecth"
+	"go/constant"
+	"go/token"
+	"reflect"
+)
+
+// All returns all bundles provided by this package merged together.
+func All() eval.Args {
+	return Merge(Bytes(), Errors(), Fmt(), Math(), Sort(), Strconv(), Strings(), Time(), Unicode())
+}
+
+// Merge returns new eval.Args which contains all arguments from bundles.
+// If the same identifier exists in several bundles then the value from the last of them is used.
+func Merge(bundles ...eval.Args) eval.Args {
+	var l int
+	for _, b := range bundles {
+		l += len(b)
+	}
+	r := make(eval.Args, l)
+	for _, b := range bundles {
+		for ident, v := range b {
+			r[ident] = v
+		}
+	}
+	return r
+}
+
+// function returns value of function f.
+func function(f interface{}) eval.Value { return eval.MakeDataRegularInterface(f) }
+
+// variable returns addressable value of variable pointed by ptr.
+func variable(ptr interface{}) eval.Value { return eval.MakeDataRegular(reflecth.ValueOfPtr(ptr)) }
+
+// typeOf returns type pointed by ptr (for example, "(*strings.Builder)(nil)").
+func typeOf(ptr interface{}) eval.Value { return eval.MakeType(reflecth.TypeOfPtr(ptr)) }
+
+// typedConst returns typed constant with value and type of x.
+// x must be of integer or string kind.
+func typedConst(x interface{}) eval.Value {
+	v := reflect.ValueOf(x)
+	var c constant.Value
+	switch k := v.Kind(); {
+	case reflecth.IsInt(k):
+		c = constant.MakeInt64(v.Int())
+	case reflecth.IsUint(k):
+		c = constant.MakeUint64(v.Uint())
+	case k == reflect.String:
+		c = constant.MakeString(v.String())
+	default:
+		panic("stdlib: unsupported kind of typed constant " + k.String())
+	}
+	return eval.MakeDataTypedConst(constanth.MustMakeTypedValue(c, v.Type()))
+}
+
+func untypedInt(x int64) eval.Value { return eval.MakeDataUntypedConst(constant.MakeInt64(x)) }
+
+func untypedString(x string) eval.Value { return eval.MakeDataUntypedConst(constant.MakeString(x)) }
+
+// untypedFloat returns untyped float constant from its literal, so constant precision is not limited by float64.
+func untypedFloat(lit string) eval.Value {
+	return eval.MakeDataUntypedConst(constant.MakeFromLiteral(lit, token.FLOAT, 0))
+}
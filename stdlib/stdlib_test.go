@@ -0,0 +1,105 @@
+package stdlib
+
+import (
+	"github.com/apaxa-go/eval"
+	"go/constant"
+	"math"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+	"unicode"
+)
+
+func TestAll(t *testing.T) {
+	type testElement struct {
+		expr string
+		r    interface{}
+	}
+
+	tests := []testElement{
+		{`fmt.Sprintf("%.2f", math.Pi)`, "3.14"},
+		{"math.MaxUint64 / 2 == math.MaxInt64", true},
+		{"math.Log2E * math.Ln2", float64(1)},
+		{"math.Sqrt(16)", float64(4)},
+		{`strings.ToUpper(strings.TrimSpace(" a "))`, "A"},
+		{`strconv.Itoa(len(bytes.Repeat([]byte{97, 98}, 3)))`, "6"},
+		{"2 * time.Second", 2 * time.Second},
+		{"time.Date(2000, time.February, 1, 0, 0, 0, 0, time.UTC).Weekday() == time.Tuesday", true},
+		{`time.RFC3339 + ""`, time.RFC3339},
+		{"unicode.IsUpper('A') && unicode.Is(unicode.Letter, 'a')", true},
+		{"sort.SearchInts([]int{1, 3, 5}, 3)", 1},
+		{`errors.Is(errors.New("a"), strconv.ErrRange)`, false},
+		{"time.Duration(1)", time.Duration(1)},
+	}
+
+	for _, test := range tests {
+		e, err := eval.ParseString(test.expr, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := e.EvalToInterface(All())
+		if err != nil || r != test.r {
+			t.Errorf("%v: expect %v (%T), got %v (%T) %v", test.expr, test.r, test.r, r, r, err)
+		}
+	}
+}
+
+func TestVariables(t *testing.T) {
+	local, errRange := time.Local, strconv.ErrRange
+	s, err := eval.ParseScriptString("time.Local = nil\nstrconv.ErrRange = nil\nunicode.Upper = nil\nreturn time.Local == nil", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, err := s.Eval(All()); err != nil || r.Data().Kind() != eval.UntypedBool || !r.Data().UntypedBool() {
+		t.Errorf("expect %v, got %v %v", true, r, err)
+	}
+	if time.Local != local || strconv.ErrRange != errRange || unicode.Upper == nil {
+		t.Error("script must not modify variables of standard library")
+	}
+
+	if v := Strconv()["strconv.ErrRange"]; !v.Data().Regular().CanAddr() || v.Data().Regular().Type() != reflect.TypeOf((*error)(nil)).Elem() {
+		t.Errorf("expect addressable variable of type error, got %v", v)
+	}
+}
+
+func TestMath(t *testing.T) {
+	// Untyped constants must be at least as precise as math constants.
+	consts := map[string]float64{
+		"math.E": math.E, "math.Pi": math.Pi, "math.Phi": math.Phi,
+		"math.Sqrt2": math.Sqrt2, "math.SqrtE": math.SqrtE, "math.SqrtPi": math.SqrtPi, "math.SqrtPhi": math.SqrtPhi,
+		"math.Ln2": math.Ln2, "math.Log2E": math.Log2E, "math.Ln10": math.Ln10, "math.Log10E": math.Log10E,
+		"math.MaxFloat32": math.MaxFloat32, "math.SmallestNonzeroFloat32": math.SmallestNonzeroFloat32,
+		"math.MaxFloat64": math.MaxFloat64, "math.SmallestNonzeroFloat64": math.SmallestNonzeroFloat64,
+	}
+	args := Math()
+	for ident, want := range consts {
+		v := args[ident]
+		if v.Kind() != eval.Datas || v.Data().Kind() != eval.UntypedConst {
+			t.Errorf("%v: expect untyped constant, got %v", ident, v)
+			continue
+		}
+		if got, _ := constant.Float64Val(v.Data().UntypedConst()); got != want {
+			t.Errorf("%v: expect %v, got %v", ident, want, got)
+		}
+	}
+}
+
+func TestTime(t *testing.T) {
+	v := Time()["time.Hour"]
+	if v.Kind() != eval.Datas || v.Data().Kind() != eval.TypedConst || v.Data().TypedConst().Type() != reflect.TypeOf(time.Duration(0)) {
+		t.Errorf("expect typed constant of type time.Duration, got %v", v)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a := eval.ArgsFromInterfaces(eval.ArgsI{"a": 1, "b": 2})
+	b := eval.ArgsFromInterfaces(eval.ArgsI{"b": 3})
+	r := Merge(a, b, nil)
+	if len(r) != 2 || r["a"] != a["a"] || r["b"] != b["b"] {
+		t.Errorf("unexpected merge result %v", r)
+	}
+	if len(a) != 2 || len(b) != 1 {
+		t.Error("merge must not modify bundles")
+	}
+}
@@ -0,0 +1,41 @@
+package stdlib
+
+import (
+	"github.com/apaxa-go/eval"
+	"strconv"
+)
+
+// Strconv returns arguments for package strconv.
+func Strconv() eval.Args {
+	return eval.Args{
+		// Constants
+		"strconv.IntSize": untypedInt(strconv.IntSize),
+
+		// Variables
+		"strconv.ErrRange":  variable(&strconv.ErrRange),
+		"strconv.ErrSyntax": variable(&strconv.ErrSyntax),
+
+		// Types
+		"strconv.NumError": typeOf((*strconv.NumError)(nil)),
+
+		// Functions
+		"strconv.AppendInt":    function(strconv.AppendInt),
+		"strconv.AppendQuote":  function(strconv.AppendQuote),
+		"strconv.Atoi":         function(strconv.Atoi),
+		"strconv.FormatBool":   function(strconv.FormatBool),
+		"strconv.FormatFloat":  function(strconv.FormatFloat),
+		"strconv.FormatInt":    function(strconv.FormatInt),
+		"strconv.FormatUint":   function(strconv.FormatUint),
+		"strconv.IsPrint":      function(strconv.IsPrint),
+		"strconv.Itoa":         function(strconv.Itoa),
+		"strconv.ParseBool":    function(strconv.ParseBool),
+		"strconv.ParseFloat":   function(strconv.ParseFloat),
+		"strconv.ParseInt":     function(strconv.ParseInt),
+		"strconv.ParseUint":    function(strconv.ParseUint),
+		"strconv.Quote":        function(strconv.Quote),
+		"strconv.QuoteRune":    function(strconv.QuoteRune),
+		"strconv.QuoteToASCII": function(strconv.QuoteToASCII),
+		"strconv.Unquote":      function(strconv.Unquote),
+		"strconv.UnquoteChar":  function(strconv.UnquoteChar),
+	}
+}
@@ -0,0 +1,64 @@
+package stdlib
+
+import (
+	"github.com/apaxa-go/eval"
+	"strings"
+)
+
+// Strings returns arguments for package strings.
+func Strings() eval.Args {
+	return eval.Args{
+		// Types
+		"strings.Builder":  typeOf((*strings.Builder)(nil)),
+		"strings.Reader":   typeOf((*strings.Reader)(nil)),
+		"strings.Replacer": typeOf((*strings.Replacer)(nil)),
+
+		// Functions
+		"strings.Compare":       function(strings.Compare),
+		"strings.Contains":      function(strings.Contains),
+		"strings.ContainsAny":   function(strings.ContainsAny),
+		"strings.ContainsRune":  function(strings.ContainsRune),
+		"strings.Count":         function(strings.Count),
+		"strings.Cut":           function(strings.Cut),
+		"strings.CutPrefix":     function(strings.CutPrefix),
+		"strings.CutSuffix":     function(strings.CutSuffix),
+		"strings.EqualFold":     function(strings.EqualFold),
+		"strings.Fields":        function(strings.Fields),
+		"strings.FieldsFunc":    function(strings.FieldsFunc),
+		"strings.HasPrefix":     function(strings.HasPrefix),
+		"strings.HasSuffix":     function(strings.HasSuffix),
+		"strings.Index":         function(strings.Index),
+		"strings.IndexAny":      function(strings.IndexAny),
+		"strings.IndexByte":     function(strings.IndexByte),
+		"strings.IndexFunc":     function(strings.IndexFunc),
+		"strings.IndexRune":     function(strings.IndexRune),
+		"strings.Join":          function(strings.Join),
+		"strings.LastIndex":     function(strings.LastIndex),
+		"strings.LastIndexAny":  function(strings.LastIndexAny),
+		"strings.LastIndexByte": function(strings.LastIndexByte),
+		"strings.LastIndexFunc": function(strings.LastIndexFunc),
+		"strings.Map":           function(strings.Map),
+		"strings.NewReader":     function(strings.NewReader),
+		"strings.NewReplacer":   function(strings.NewReplacer),
+		"strings.Repeat":        function(strings.Repeat),
+		"strings.Replace":       function(strings.Replace),
+		"strings.ReplaceAll":    function(strings.ReplaceAll),
+		"strings.Split":         function(strings.Split),
+		"strings.SplitAfter":    function(strings.SplitAfter),
+		"strings.SplitAfterN":   function(strings.SplitAfterN),
+		"strings.SplitN":        function(strings.SplitN),
+		"strings.ToLower":       function(strings.ToLower),
+		"strings.ToTitle":       function(strings.ToTitle),
+		"strings.ToUpper":       function(strings.ToUpper),
+		"strings.ToValidUTF8":   function(strings.ToValidUTF8),
+		"strings.Trim":          function(strings.Trim),
+		"strings.TrimFunc":      function(strings.TrimFunc),
+		"strings.TrimLeft":      function(strings.TrimLeft),
+		"strings.TrimLeftFunc":  function(strings.TrimLeftFunc),
+		"strings.TrimPrefix":    function(strings.TrimPrefix),
+		"strings.TrimRight":     function(strings.TrimRight),
+		"strings.TrimRightFunc": function(strings.TrimRightFunc),
+		"strings.TrimSpace":     function(strings.TrimSpace),
+		"strings.TrimSuffix":    function(strings.TrimSuffix),
+	}
+}
@@ -0,0 +1,87 @@
+package stdlib
+
+import (
+	"github.com/apaxa-go/eval"
+	"time"
+)
+
+// Time returns arguments for package time.
+func Time() eval.Args {
+	return eval.Args{
+		// Layouts
+		"time.Layout":      untypedString(time.Layout),
+		"time.ANSIC":       untypedString(time.ANSIC),
+		"time.UnixDate":    untypedString(time.UnixDate),
+		"time.RubyDate":    untypedString(time.RubyDate),
+		"time.RFC822":      untypedString(time.RFC822),
+		"time.RFC822Z":     untypedString(time.RFC822Z),
+		"time.RFC850":      untypedString(time.RFC850),
+		"time.RFC1123":     untypedString(time.RFC1123),
+		"time.RFC1123Z":    untypedString(time.RFC1123Z),
+		"time.RFC3339":     untypedString(time.RFC3339),
+		"time.RFC3339Nano": untypedString(time.RFC3339Nano),
+		"time.Kitchen":     untypedString(time.Kitchen),
+		"time.Stamp":       untypedString(time.Stamp),
+		"time.StampMilli":  untypedString(time.StampMilli),
+		"time.StampMicro":  untypedString(time.StampMicro),
+		"time.StampNano":   untypedString(time.StampNano),
+		"time.DateTime":    untypedString(time.DateTime),
+		"time.DateOnly":    untypedString(time.DateOnly),
+		"time.TimeOnly":    untypedString(time.TimeOnly),
+
+		// Durations
+		"time.Nanosecond":  typedConst(time.Nanosecond),
+		"time.Microsecond": typedConst(time.Microsecond),
+		"time.Millisecond": typedConst(time.Millisecond),
+		"time.Second":      typedConst(time.Second),
+		"time.Minute":      typedConst(time.Minute),
+		"time.Hour":        typedConst(time.Hour),
+
+		// Months
+		"time.January":   typedConst(time.January),
+		"time.February":  typedConst(time.February),
+		"time.March":     typedConst(time.March),
+		"time.April":     typedConst(time.April),
+		"time.May":       typedConst(time.May),
+		"time.June":      typedConst(time.June),
+		"time.July":      typedConst(time.July),
+		"time.August":    typedConst(time.August),
+		"time.September": typedConst(time.September),
+		"time.October":   typedConst(time.October),
+		"time.November":  typedConst(time.November),
+		"time.December":  typedConst(time.December),
+
+		// Weekdays
+		"time.Sunday":    typedConst(time.Sunday),
+		"time.Monday":    typedConst(time.Monday),
+		"time.Tuesday":   typedConst(time.Tuesday),
+		"time.Wednesday": typedConst(time.Wednesday),
+		"time.Thursday":  typedConst(time.Thursday),
+		"time.Friday":    typedConst(time.Friday),
+		"time.Saturday":  typedConst(time.Saturday),
+
+		// Variables
+		"time.Local": variable(&time.Local),
+		"time.UTC":   variable(&time.UTC),
+
+		// Types
+		"time.Duration": typeOf((*time.Duration)(nil)),
+		"time.Location": typeOf((*time.Location)(nil)),
+		"time.Month":    typeOf((*time.Month)(nil)),
+		"time.Time":     typeOf((*time.Time)(nil)),
+		"time.Weekday":  typeOf((*time.Weekday)(nil)),
+
+		// Functions
+		"time.Date":            function(time.Date),
+		"time.FixedZone":       function(time.FixedZone),
+		"time.Now":             function(time.Now),
+		"time.Parse":           function(time.Parse),
+		"time.ParseDuration":   function(time.ParseDuration),
+		"time.ParseInLocation": function(time.ParseInLocation),
+		"time.Since":           function(time.Since),
+		"time.Unix":            function(time.Unix),
+		"time.UnixMicro":       function(time.UnixMicro),
+		"time.UnixMilli":       function(time.UnixMilli),
+		"time.Until":           function(time.Until),
+	}
+}
@@ -0,0 +1,58 @@
+package stdlib
+
+import (
+	"github.com/apaxa-go/eval"
+	"unicode"
+)
+
+// Unicode returns arguments for package unicode.
+// Rune constants are passed as untyped integer constants.
+func Unicode() eval.Args {
+	return eval.Args{
+		// Constants
+		"unicode.MaxRune":         untypedInt(unicode.MaxRune),
+		"unicode.ReplacementChar": untypedInt(unicode.ReplacementChar),
+		"unicode.MaxASCII":        untypedInt(unicode.MaxASCII),
+		"unicode.MaxLatin1":       untypedInt(unicode.MaxLatin1),
+		"unicode.UpperCase":       untypedInt(unicode.UpperCase),
+		"unicode.LowerCase":       untypedInt(unicode.LowerCase),
+		"unicode.TitleCase":       untypedInt(unicode.TitleCase),
+		"unicode.MaxCase":         untypedInt(unicode.MaxCase),
+
+		// Variables
+		"unicode.Digit":  variable(&unicode.Digit),
+		"unicode.Letter": variable(&unicode.Letter),
+		"unicode.Lower":  variable(&unicode.Lower),
+		"unicode.Number": variable(&unicode.Number),
+		"unicode.Punct":  variable(&unicode.Punct),
+		"unicode.Space":  variable(&unicode.Space),
+		"unicode.Symbol": variable(&unicode.Symbol),
+		"unicode.Title":  variable(&unicode.Title),
+		"unicode.Upper":  variable(&unicode.Upper),
+
+		// Types
+		"unicode.RangeTable": typeOf((*unicode.RangeTable)(nil)),
+
+		// Functions
+		"unicode.In":         function(unicode.In),
+		"unicode.Is":         function(unicode.Is),
+		"unicode.IsControl":  function(unicode.IsControl),
+		"unicode.IsDigit":    function(unicode.IsDigit),
+		"unicode.IsGraphic":  function(unicode.IsGraphic),
+		"unicode.IsLetter":   function(unicode.IsLetter),
+		"unicode.IsLower":    function(unicode.IsLower),
+		"unicode.IsMark":     function(unicode.IsMark),
+		"unicode.IsNumber":   function(unicode.IsNumber),
+		"unicode.IsPrint":    function(unicode.IsPrint),
+		"unicode.IsPunct":    function(unicode.IsPunct),
+		"unicode.IsSpace":    function(unicode.IsSpace),
+		"unicode.IsSymbol":   function(unicode.IsSymbol),
+		"unicode.IsTitle":    function(unicode.IsTitle),
+		"unicode.IsUpper":    function(unicode.IsUpper),
+		"unicode.SimpleFold": function(unicode.SimpleFold),
+		"unicode.To":         function(unicode.To),
+		"unicode.ToLower":    function(unicode.ToLower),
+		"unicode.ToTitle":    function(unicode.ToTitle),
+		"unicode.ToUpper":    function(unicode.ToUpper),
+	}
+}
@@ -159,6 +159,7 @@ var (
 // Required types for tests
 type (
 	myInt    int
+	myBool   bool
 	myStr    string
 	myStruct struct {
 		I int
@@ -182,6 +183,15 @@ func (x myImplementation) MyMethod() {
 }
 
 // Required functions for tests
+func myApply(f func(int) int, x int) int { return f(x) }
+func myFilter(x []int, f func(int) bool) (r []int) {
+	for _, v := range x {
+		if f(v) {
+			r = append(r, v)
+		}
+	}
+	return
+}
 func myDiv(k int, x ...int) []int {
 	for i := range x {
 		x[i] /= k
@@ -352,6 +362,38 @@ var testsExpr = testExprCatalog{
 		{`"str0">=string("str1")`, nil, MakeDataUntypedBool(false), false},
 		{"a==1", ArgsFromInterfaces(ArgsI{"a": "str"}), nil, true},
 		{"1==a", ArgsFromInterfaces(ArgsI{"a": "str"}), nil, true},
+		// Conditional logical
+		{"true&&false", nil, MakeDataUntypedConst(constant.MakeBool(false)), false},
+		{"true||false", nil, MakeDataUntypedConst(constant.MakeBool(true)), false},
+		{"bool(true)&&false", nil, MakeDataTypedConst(constanth.MustMakeTypedValue(constant.MakeBool(false), reflecth.TypeBool())), false},
+		{"a&&b", ArgsFromInterfaces(ArgsI{"a": true, "b": true}), MakeDataRegularInterface(true), false},
+		{"a||b", ArgsFromInterfaces(ArgsI{"a": false, "b": false}), MakeDataRegularInterface(false), false},
+		{"a&&b", ArgsFromInterfaces(ArgsI{"a": true, "b": 1}), nil, true},
+		{"a&&b", ArgsFromInterfaces(ArgsI{"a": true, "b": myBool(true)}), nil, true},
+		{"a&&1", ArgsFromInterfaces(ArgsI{"a": true}), nil, true},
+		{"a>1&&b<2", ArgsFromInterfaces(ArgsI{"a": 2, "b": 1}), MakeDataUntypedBool(true), false},
+		{"a>1||b<2", ArgsFromInterfaces(ArgsI{"a": 1, "b": 2}), MakeDataUntypedBool(false), false},
+		{"a>1&&true", ArgsFromInterfaces(ArgsI{"a": 2}), MakeDataUntypedBool(true), false},
+		{"a>1&&b", ArgsFromInterfaces(ArgsI{"a": 2, "b": true}), MakeDataRegularInterface(true), false},
+		{"a>1&&b", ArgsFromInterfaces(ArgsI{"a": 2, "b": myBool(true)}), MakeDataRegularInterface(myBool(true)), false},
+		{"a>1&&myBool(true)", Args{"a": MakeDataRegularInterface(2), "myBool": MakeTypeInterface(myBool(false))}, MakeDataRegularInterface(myBool(true)), false},
+		{"a>1&&1", ArgsFromInterfaces(ArgsI{"a": 2}), nil, true},
+		{"a>1&&nil", ArgsFromInterfaces(ArgsI{"a": 2}), nil, true},
+		// Conditional logical: short-circuit
+		{"p!=nil&&p.F==2", ArgsFromInterfaces(ArgsI{"p": (*SampleStruct)(nil)}), MakeDataUntypedBool(false), false},
+		{"p!=nil&&p.F==2", ArgsFromInterfaces(ArgsI{"p": &SampleStruct{2}}), MakeDataUntypedBool(true), false},
+		{"p==nil||p.F==2", ArgsFromInterfaces(ArgsI{"p": (*SampleStruct)(nil)}), MakeDataUntypedBool(true), false},
+		{"p!=nil&&p.Unknown", ArgsFromInterfaces(ArgsI{"p": (*SampleStruct)(nil)}), nil, true},
+		{"p!=nil&&p.F", ArgsFromInterfaces(ArgsI{"p": (*SampleStruct)(nil)}), nil, true},
+		{"false&&f()", ArgsFromInterfaces(ArgsI{"f": func() bool { panic("must not be called") }}), MakeDataRegularInterface(false), false},
+		{"true||f()", ArgsFromInterfaces(ArgsI{"f": func() bool { panic("must not be called") }}), MakeDataRegularInterface(true), false},
+		{"a&&f()", ArgsFromInterfaces(ArgsI{"a": false, "f": func() bool { panic("must not be called") }}), MakeDataRegularInterface(false), false},
+		{"a||<-c", ArgsFromInterfaces(ArgsI{"a": true, "c": make(chan bool)}), MakeDataRegularInterface(true), false},
+		{"false&&f()", ArgsFromInterfaces(ArgsI{"f": func() int { panic("must not be called") }}), nil, true},
+		{"false&&f(1)", ArgsFromInterfaces(ArgsI{"f": func() bool { panic("must not be called") }}), nil, true},
+		{"false&&1", nil, nil, true},
+		{"a&&s[5]", ArgsFromInterfaces(ArgsI{"a": false, "s": []bool{}}), MakeDataRegularInterface(false), false},
+		{"a&&s[5]", ArgsFromInterfaces(ArgsI{"a": true, "s": []bool{}}), nil, true},
 	},
 	"basic-lit": []testExprElement{},
 	"call": []testExprElement{
@@ -664,6 +706,29 @@ var testsExpr = testExprCatalog{
 		{"interface{}", nil, MakeType(reflecth.TypeEmptyInterface()), false},
 		{"interface{M1(int)string}", nil, nil, true},
 	},
+	"func-lit": {
+		{"apply(func(x int) int { return x * k }, 3)", Args{"apply": MakeDataRegularInterface(myApply), "k": MakeDataRegularInterface(2)}, MakeDataRegularInterface(6), false},
+		{"apply(func(x int) int { return x * k }, 3)", Args{"apply": MakeDataRegularInterface(myApply), "k": MakeDataUntypedConst(constant.MakeInt64(2))}, MakeDataRegularInterface(6), false},
+		{"apply(func(x int) (r int) { return x + r + 1 }, 3)", Args{"apply": MakeDataRegularInterface(myApply)}, MakeDataRegularInterface(4), false},
+		{"apply(func(_ int) int { return 1 }, 3)", Args{"apply": MakeDataRegularInterface(myApply)}, MakeDataRegularInterface(1), false},
+		{"apply(func(int) int { return 1 }, 3)", Args{"apply": MakeDataRegularInterface(myApply)}, MakeDataRegularInterface(1), false},
+		{"apply(func(x int) int { return apply(func(y int) int { return x * y }, x) }, 3)", Args{"apply": MakeDataRegularInterface(myApply)}, MakeDataRegularInterface(9), false},
+		{"len(filter(a, func(x int) bool { return x%2 == 0 }))", Args{"filter": MakeDataRegularInterface(myFilter), "a": MakeDataRegularInterface([]int{1, 2, 3, 4})}, MakeDataRegularInterface(2), false},
+		{"f(1, 2)", Args{"f": MakeDataRegularInterface(func(g func(...int) int) int { return g(1, 2) })}, nil, true},
+		{"f(func(x ...int) int { return len(x) })", Args{"f": MakeDataRegularInterface(func(g func(...int) int) int { return g(1, 2) })}, MakeDataRegularInterface(2), false},
+		{"f(func(x, y int) int { return x - y })", Args{"f": MakeDataRegularInterface(func(g func(int, int) int) int { return g(3, 2) })}, MakeDataRegularInterface(1), false},
+		{"apply(func(x int) int { return s[x] }, 1)", Args{"apply": MakeDataRegularInterface(myApply), "s": MakeDataRegularInterface([]int{5, 6})}, MakeDataRegularInterface(6), false},
+		{"apply(func(x int) int { return s[x] }, 2)", Args{"apply": MakeDataRegularInterface(myApply), "s": MakeDataRegularInterface([]int{5, 6})}, nil, true},
+		{"apply(func(x int) int { return x+\"1\" }, 2)", Args{"apply": MakeDataRegularInterface(myApply)}, nil, true},
+		{"apply(func(x int) int { return int8(x) }, 2)", Args{"apply": MakeDataRegularInterface(myApply)}, nil, true},
+		{"apply(func(x int) int { return y }, 2)", Args{"apply": MakeDataRegularInterface(myApply)}, nil, true},
+		{"apply(func(x int) int { x++; return x }, 2)", Args{"apply": MakeDataRegularInterface(myApply)}, nil, true},
+		{"apply(func(x int) int { }, 2)", Args{"apply": MakeDataRegularInterface(myApply)}, nil, true},
+		{"apply(func(x int) int { return x, x }, 2)", Args{"apply": MakeDataRegularInterface(myApply)}, nil, true},
+		{"apply(func(x int) { return x }, 2)", Args{"apply": MakeDataRegularInterface(myApply)}, nil, true},
+		{"apply(func(x int) (int, int) { return x }, 2)", Args{"apply": MakeDataRegularInterface(myApply)}, nil, true},
+		{"apply(func(x int) string { return \"\" }, 2)", Args{"apply": MakeDataRegularInterface(myApply)}, nil, true},
+	},
 	"expr": []testExprElement{
 		{"(f.(func(int)(string)))(123)", ArgsFromRegulars(ArgsR{"f": reflecth.ValueOfPtr(&tmp1)}), MakeDataRegularInterface("123"), false},
 		{"((func(int)(string))(f))(123)", ArgsFromInterfaces(ArgsI{"f": strconvh.FormatInt}), MakeDataRegularInterface("123"), false},
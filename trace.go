@@ -0,0 +1,42 @@
+package eval
+
+import (
+	"github.com/apaxa-go/helper/goh/asth"
+	"go/ast"
+)
+
+// Tracer observes evaluation node by node (see EvalOptions.Tracer).
+// It may be used to log or visualize how expression arrives at its result.
+// Calls are properly nested: Enter and Exit of subexpressions are called between Enter and Exit of expression containing them.
+// Nodes computed at compile time (see Compile) are reported with precomputed values and without subexpressions.
+// Top level call which may return any number of results (evaluated by EvalMulti or used as statement of script) is reported with its first result (or without result if called function has no results).
+// Tracer is called only during evaluation (Eval* methods of Expression, Program and Script), Compile, Check, CheckAll and Simplify do not call it.
+type Tracer interface {
+	// Enter is called before evaluation of node n located at pos.
+	// n is an expression (ast.Expr) or a statement of script (ast.Stmt).
+	Enter(n ast.Node, pos asth.Position)
+	// Exit is called after evaluation of node n located at pos (the same as passed to Enter).
+	// For expression exactly one of r and err is non nil (except top level call of function without results).
+	// For statement r is non nil only if return statement was executed (n itself or statement nested in it).
+	// err (if any) is of type Error, it may be positioned at subexpression of n.
+	// Exit is not called if evaluation of n panics (see EvalOptions.Panics).
+	Exit(n ast.Node, pos asth.Position, r Value, err error)
+}
+
+// traceExpr evaluates e reporting it to tracer.
+func (expr *Expression) traceExpr(tracer Tracer, e ast.Expr, args Args) (r Value, err *posError) {
+	pos := asth.MakePosition(e.Pos(), e.End(), expr.fset)
+	tracer.Enter(e, pos)
+	r, err = expr.astExprValue(e, args)
+	tracer.Exit(e, pos, r, err.error(expr.fset))
+	return
+}
+
+// traceStmt executes s reporting it to tracer.
+func (expr *Expression) traceStmt(tracer Tracer, s ast.Stmt, b *scriptBlock) (ctl scriptCtl, r Value, err *posError) {
+	pos := asth.MakePosition(s.Pos(), s.End(), expr.fset)
+	tracer.Enter(s, pos)
+	ctl, r, err = expr.execStmtNode(s, b)
+	tracer.Exit(s, pos, r, err.error(expr.fset))
+	return
+}
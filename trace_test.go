@@ -0,0 +1,150 @@
+package eval
+
+import (
+	"errors"
+	"github.com/apaxa-go/helper/goh/asth"
+	"go/ast"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// recordTracer records evaluated nodes as source of expressions (statements as source of first line).
+type recordTracer struct {
+	src   []string // lines of source
+	depth int
+	log   []string
+}
+
+func (t *recordTracer) node(pos asth.Position) string {
+	line := t.src[pos.Line-1]
+	if pos.EndLine != pos.Line {
+		return line[pos.Column-1:] + "..."
+	}
+	return line[pos.Column-1 : pos.EndColumn-1]
+}
+
+func (t *recordTracer) Enter(n ast.Node, pos asth.Position) {
+	t.log = append(t.log, strings.Repeat(" ", t.depth)+t.node(pos))
+	t.depth++
+}
+
+func (t *recordTracer) Exit(n ast.Node, pos asth.Position, r Value, err error) {
+	t.depth--
+	s := strings.Repeat(" ", t.depth) + t.node(pos) + " ="
+	if r != nil {
+		s += " " + r.String()
+	}
+	if err != nil {
+		var evalErr Error
+		if !errors.As(err, &evalErr) {
+			s += " unexpected error type"
+		}
+		s += " error"
+	}
+	t.log = append(t.log, s)
+}
+
+func TestEvalOptions_Tracer(t *testing.T) {
+	args := ArgsFromInterfaces(ArgsI{"a": 1, "s": "str"})
+
+	type testElement struct {
+		expr string
+		log  []string
+	}
+	tests := []testElement{
+		{"a + len(s)", []string{
+			"a + len(s)",
+			" a",
+			" a = 1 (type int)",
+			" len(s)",
+			"  len",
+			"  len = built-in function value len",
+			"  s",
+			"  s = str (type string)",
+			" len(s) = 3 (type int)",
+			"a + len(s) = 4 (type int)",
+		}},
+		{"a + b", []string{
+			"a + b",
+			" a",
+			" a = 1 (type int)",
+			" b",
+			" b = error",
+			"a + b = error",
+		}},
+	}
+
+	for _, test := range tests {
+		e, err := ParseString(test.expr, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		tracer := &recordTracer{src: []string{test.expr}}
+		_, _ = e.WithOptions(EvalOptions{Tracer: tracer}).EvalRaw(args)
+		if !reflect.DeepEqual(tracer.log, test.log) {
+			t.Errorf("%v: expect\n%v\ngot\n%v", test.expr, strings.Join(test.log, "\n"), strings.Join(tracer.log, "\n"))
+		}
+	}
+
+	// Compiled: precomputed nodes are reported without subexpressions, tracer is not called by Compile
+	src := "a + 2*3"
+	e, err := ParseString(src, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracer := &recordTracer{src: []string{src}}
+	p, err := e.WithOptions(EvalOptions{Tracer: tracer}).Compile(ArgTypes(args))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracer.log) != 0 {
+		t.Errorf("Compile must not call tracer, got %v", tracer.log)
+	}
+	if _, err = p.Eval(args); err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{
+		"a + 2*3",
+		" a",
+		" a = 1 (type int)",
+		" 2*3",
+		" 2*3 = 6 (type untyped constant)",
+		"a + 2*3 = 7 (type int)",
+	}
+	if !reflect.DeepEqual(tracer.log, expect) {
+		t.Errorf("expect\n%v\ngot\n%v", strings.Join(expect, "\n"), strings.Join(tracer.log, "\n"))
+	}
+
+	// Script statements
+	src = "x := a\nif x > 0 {\nreturn x\n}\nreturn 0"
+	s, err := ParseScriptString(src, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracer = &recordTracer{src: strings.Split(src, "\n")}
+	if _, err = s.WithOptions(EvalOptions{Tracer: tracer}).Eval(args); err != nil {
+		t.Fatal(err)
+	}
+	expect = []string{
+		"x := a",
+		" a",
+		" a = 1 (type int)",
+		"x := a =",
+		"if x > 0 {...",
+		" x > 0",
+		"  x",
+		"  x = 1 (type int)",
+		"  0",
+		"  0 = 0 (type untyped constant)",
+		" x > 0 = true (type untyped bool)",
+		" return x",
+		"  x",
+		"  x = 1 (type int)",
+		" return x = 1 (type int)",
+		"if x > 0 {... = 1 (type int)",
+	}
+	if !reflect.DeepEqual(tracer.log, expect) {
+		t.Errorf("expect\n%v\ngot\n%v", strings.Join(expect, "\n"), strings.Join(tracer.log, "\n"))
+	}
+}
@@ -16,10 +16,12 @@ const (
 	Type                         // type
 	BuiltInFunc                  // built-in function
 	Package                      // package
+	GenericFunc                  // generic function (see MakeGenericFunc)
+	GenericType                  // generic type (see MakeGenericType)
 )
 
 // Value used to store arguments passed to/returned from expression.
-// It can stores: data, type, built-in function and package.
+// It can stores: data, type, built-in function, package, generic function and generic type.
 // GoLang valid expression can return only data, all other kind is primary used internally while evaluation.
 type Value interface {
 	// Kind returns current kind of value represented by a Value.
@@ -17,6 +17,8 @@ func TestValue_String(t *testing.T) {
 		{MakeType(reflecth.TypeBool()), "type value bool"},
 		{MakeBuiltInFunc("len"), "built-in function value len"},
 		{MakePackage(ArgsFromInterfaces(ArgsI{"SomeVar": 1})), "package (exports: SomeVar)"},
+		{MakeGenericType("T[K any]", nil), "generic type value T[K any]"},
+		{MakeGenericFunc("F[K any]()", nil), "generic function value F[K any]()"},
 	}
 
 	for _, test := range tests {